@@ -0,0 +1,130 @@
+package dotnetcoreaspnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// AppAnalysis describes whether the application needs ASP.NET Core, along
+// with the reason for that conclusion.
+type AppAnalysis struct {
+	UsesASPNET bool
+	Reason     string
+}
+
+type ApplicationAnalyzer struct {
+	runtimeConfigParser RuntimeConfigParser
+	projectParser       ProjectFileParser
+	logger              scribe.Emitter
+}
+
+func NewApplicationAnalyzer(logger scribe.Emitter) ApplicationAnalyzer {
+	return ApplicationAnalyzer{
+		runtimeConfigParser: NewRuntimeConfigParser(),
+		projectParser:       NewProjectFileParser(),
+		logger:              logger,
+	}
+}
+
+// Analyze looks for signs that the application in the working directory
+// needs ASP.NET Core: a reference to the Microsoft.AspNetCore.App framework
+// in its *.runtimeconfig.json, ASP.NET Core libraries in its *.deps.json, or
+// a project that uses the web SDK or references the framework. A *.deps.json
+// that can not be parsed is reported and skipped, since the analysis only
+// informs a warning.
+func (a ApplicationAnalyzer) Analyze(workingDir string) (AppAnalysis, error) {
+	config, err := a.runtimeConfigParser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+	if err != nil {
+		return AppAnalysis{}, err
+	}
+
+	if config.ASPNETVersion != "" {
+		return AppAnalysis{
+			UsesASPNET: true,
+			Reason:     fmt.Sprintf("%s references the Microsoft.AspNetCore.App framework", filepath.Base(config.Path)),
+		}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(workingDir, "*.deps.json"))
+	if err != nil {
+		return AppAnalysis{}, err
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return AppAnalysis{}, err
+		}
+
+		library, err := aspnetLibrary(content)
+		if err != nil {
+			a.logger.Subprocess("WARNING: Skipping %s, which could not be parsed: %s", filepath.Base(file), err)
+			a.logger.Break()
+			continue
+		}
+
+		if library != "" {
+			return AppAnalysis{
+				UsesASPNET: true,
+				Reason:     fmt.Sprintf("%s references %s", filepath.Base(file), library),
+			}, nil
+		}
+	}
+
+	projectFile, err := a.projectParser.FindProjectFile(workingDir)
+	if err != nil {
+		return AppAnalysis{}, err
+	}
+
+	if projectFile != "" {
+		usesASPNET, err := a.projectParser.UsesASPNET(projectFile)
+		if err != nil {
+			return AppAnalysis{}, err
+		}
+
+		if usesASPNET {
+			return AppAnalysis{
+				UsesASPNET: true,
+				Reason:     fmt.Sprintf("%s uses the web SDK or references the Microsoft.AspNetCore.App framework", filepath.Base(projectFile)),
+			}, nil
+		}
+	}
+
+	return AppAnalysis{
+		Reason: "no reference to ASP.NET Core was found in a *.runtimeconfig.json, *.deps.json or project file",
+	}, nil
+}
+
+// aspnetLibrary returns the first ASP.NET Core library listed in the given
+// *.deps.json content, if any.
+func aspnetLibrary(content []byte) (string, error) {
+	var deps struct {
+		Libraries map[string]interface{} `json:"libraries"`
+	}
+
+	err := json.Unmarshal(content, &deps)
+	if err != nil {
+		return "", err
+	}
+
+	var libraries []string
+	for library := range deps.Libraries {
+		if strings.HasPrefix(library, "Microsoft.AspNetCore.") {
+			libraries = append(libraries, library)
+		}
+	}
+
+	if len(libraries) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(libraries)
+
+	return libraries[0], nil
+}
@@ -0,0 +1,148 @@
+package dotnetcoreaspnet_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testApplicationAnalyzer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		buffer     *bytes.Buffer
+		analyzer   dotnetcoreaspnet.ApplicationAnalyzer
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer = bytes.NewBuffer(nil)
+		analyzer = dotnetcoreaspnet.NewApplicationAnalyzer(scribe.NewEmitter(buffer))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("when the runtimeconfig.json references the ASP.NET Core framework", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "framework": {
+      "name": "Microsoft.AspNetCore.App",
+      "version": "6.0.0"
+    }
+  }
+}`), 0600)).To(Succeed())
+		})
+
+		it("reports that the application uses ASP.NET Core", func() {
+			analysis, err := analyzer.Analyze(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis).To(Equal(dotnetcoreaspnet.AppAnalysis{
+				UsesASPNET: true,
+				Reason:     "some-app.runtimeconfig.json references the Microsoft.AspNetCore.App framework",
+			}))
+		})
+	})
+
+	context("when the deps.json lists an ASP.NET Core library", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "framework": {
+      "name": "Microsoft.NETCore.App",
+      "version": "6.0.0"
+    }
+  }
+}`), 0600)).To(Succeed())
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.deps.json"), []byte(`{
+  "libraries": {
+    "some-app/1.0.0": {"type": "project"},
+    "Microsoft.AspNetCore.Mvc.Core/2.2.5": {"type": "package"},
+    "Microsoft.AspNetCore.Authorization/2.2.0": {"type": "package"}
+  }
+}`), 0600)).To(Succeed())
+		})
+
+		it("reports that the application uses ASP.NET Core", func() {
+			analysis, err := analyzer.Analyze(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis).To(Equal(dotnetcoreaspnet.AppAnalysis{
+				UsesASPNET: true,
+				Reason:     "some-app.deps.json references Microsoft.AspNetCore.Authorization/2.2.0",
+			}))
+		})
+	})
+
+	context("when the project uses the web SDK", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net6.0</TargetFramework>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+		})
+
+		it("reports that the application uses ASP.NET Core", func() {
+			analysis, err := analyzer.Analyze(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis).To(Equal(dotnetcoreaspnet.AppAnalysis{
+				UsesASPNET: true,
+				Reason:     "some-app.csproj uses the web SDK or references the Microsoft.AspNetCore.App framework",
+			}))
+		})
+	})
+
+	context("when nothing references ASP.NET Core", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net6.0</TargetFramework>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+		})
+
+		it("reports that the application does not use ASP.NET Core", func() {
+			analysis, err := analyzer.Analyze(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis).To(Equal(dotnetcoreaspnet.AppAnalysis{
+				Reason: "no reference to ASP.NET Core was found in a *.runtimeconfig.json, *.deps.json or project file",
+			}))
+		})
+	})
+
+	context("when the deps.json is malformed", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.deps.json"), []byte("%%%"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.csproj"), []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net6.0</TargetFramework>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+		})
+
+		it("reports the deps.json and carries on with the remaining checks", func() {
+			analysis, err := analyzer.Analyze(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(analysis).To(Equal(dotnetcoreaspnet.AppAnalysis{
+				UsesASPNET: true,
+				Reason:     "some-app.csproj uses the web SDK or references the Microsoft.AspNetCore.App framework",
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring("WARNING: Skipping some-app.deps.json, which could not be parsed: invalid character '%' looking for beginning of value"))
+		})
+	})
+}
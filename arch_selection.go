@@ -0,0 +1,44 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// selectArch returns the artifact of the resolved dependency that is built for
+// the given architecture. The dependency manager selects dependencies by
+// stack alone, so the resolved artifact may have been built for another
+// architecture. Dependencies that are not listed in the catalog are returned
+// unchanged.
+func selectArch(catalogDeps []CatalogDependency, dependency postal.Dependency, arch string) (postal.Dependency, error) {
+	for _, d := range catalogDeps {
+		if d.Version == dependency.Version && d.URI == dependency.URI && d.SHA256 == dependency.SHA256 {
+			if d.SupportsArch(arch) {
+				return dependency, nil
+			}
+
+			var versions []string
+			for _, candidate := range catalogDeps {
+				if !candidate.SupportsArch(arch) {
+					continue
+				}
+
+				if candidate.Version == dependency.Version {
+					return candidate.Dependency, nil
+				}
+
+				versions = append(versions, candidate.Version)
+			}
+
+			if len(versions) == 0 {
+				return postal.Dependency{}, fmt.Errorf("no %s %s artifact is available for the %s architecture: this buildpack provides no %s versions for %s", dependency.ID, dependency.Version, arch, dependency.ID, arch)
+			}
+
+			return postal.Dependency{}, fmt.Errorf("no %s %s artifact is available for the %s architecture: request one of the versions available for %s: %s", dependency.ID, dependency.Version, arch, arch, strings.Join(versions, ", "))
+		}
+	}
+
+	return dependency, nil
+}
@@ -0,0 +1,226 @@
+package dotnetcoreaspnet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+)
+
+//go:generate faux --interface BindingResolver --output fakes/binding_resolver.go
+type BindingResolver interface {
+	Resolve(typ, provider, platformDir string) ([]servicebindings.Binding, error)
+}
+
+// ArchiveCache keeps the downloaded archives of dependencies in a directory,
+// keyed by their SHA256, so that they can be installed again without being
+// downloaded.
+type ArchiveCache struct {
+	transport       postal.Transport
+	bindingResolver BindingResolver
+	logger          scribe.Emitter
+}
+
+func NewArchiveCache(transport postal.Transport, logger scribe.Emitter) ArchiveCache {
+	return ArchiveCache{
+		transport:       transport,
+		bindingResolver: servicebindings.NewResolver(),
+		logger:          logger,
+	}
+}
+
+func (c ArchiveCache) WithBindingResolver(bindingResolver BindingResolver) ArchiveCache {
+	c.bindingResolver = bindingResolver
+	return c
+}
+
+// Fetch makes sure that the archive of the dependency is in the cache
+// directory, downloading it when it is missing or corrupted, and returns the
+// dependency with its URI pointing at the cached archive. Dependencies
+// without a SHA256 checksum and dependencies that the platform maps to
+// another location are returned unchanged.
+func (c ArchiveCache) Fetch(dependency postal.Dependency, cnbPath, platformPath, cacheDir string) (postal.Dependency, error) {
+	sum := archiveSHA256(dependency)
+	if sum == "" {
+		return dependency, nil
+	}
+
+	mapped, err := c.hasDependencyMapping(sum, platformPath)
+	if err != nil {
+		return postal.Dependency{}, err
+	}
+
+	if mapped {
+		return dependency, nil
+	}
+
+	name, err := archiveName(dependency.URI)
+	if err != nil {
+		return postal.Dependency{}, err
+	}
+
+	archive := filepath.Join(cacheDir, sum, name)
+	cachedSum, err := fileSHA256(archive)
+	switch {
+	case err == nil && cachedSum == sum:
+		c.logger.Debug.Subprocess("Using cached archive of %s %s", dependency.Name, dependency.Version)
+	case err == nil || errors.Is(err, os.ErrNotExist):
+		c.logger.Debug.Subprocess("Downloading %s %s into the download cache", dependency.Name, dependency.Version)
+		err = c.download(dependency, cnbPath, sum, archive)
+		if err != nil {
+			return postal.Dependency{}, err
+		}
+	default:
+		return postal.Dependency{}, fmt.Errorf("failed to read cached archive: %w", err)
+	}
+
+	// the transport resolves file URIs relative to the buildpack
+	rel, err := filepath.Rel(cnbPath, archive)
+	if err != nil {
+		return postal.Dependency{}, fmt.Errorf("failed to locate cached archive: %w", err)
+	}
+
+	dependency.URI = fmt.Sprintf("file://%s", filepath.ToSlash(rel))
+
+	return dependency, nil
+}
+
+// Prune removes the archives of every dependency other than the given ones
+// from the cache directory.
+func (c ArchiveCache) Prune(cacheDir string, keep []postal.Dependency) error {
+	files, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to prune download cache: %w", err)
+	}
+
+	sums := map[string]bool{}
+	for _, dependency := range keep {
+		sums[archiveSHA256(dependency)] = true
+	}
+
+	for _, f := range files {
+		if sums[f.Name()] {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(cacheDir, f.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to prune download cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c ArchiveCache) download(dependency postal.Dependency, cnbPath, sum, archive string) error {
+	bundle, err := c.transport.Drop(cnbPath, dependency.URI)
+	if err != nil {
+		return fmt.Errorf("failed to fetch dependency: %w", err)
+	}
+	defer bundle.Close()
+
+	err = os.MkdirAll(filepath.Dir(archive), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to cache dependency: %w", err)
+	}
+
+	// the archive is written to a temporary file first so that an interrupted
+	// download never leaves a partial archive in the cache
+	file, err := os.CreateTemp(filepath.Dir(archive), "download")
+	if err != nil {
+		return fmt.Errorf("failed to cache dependency: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = io.Copy(file, cargo.NewValidatedReader(bundle, fmt.Sprintf("sha256:%s", sum)))
+	if err != nil {
+		if errors.Is(err, cargo.ChecksumValidationError) {
+			return errors.New("failed to validate dependency: checksum does not match")
+		}
+
+		return fmt.Errorf("failed to cache dependency: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to cache dependency: %w", err)
+	}
+
+	err = os.Rename(file.Name(), archive)
+	if err != nil {
+		return fmt.Errorf("failed to cache dependency: %w", err)
+	}
+
+	return nil
+}
+
+// hasDependencyMapping returns whether the platform provides a dependency
+// mapping binding for the archive with the given SHA256.
+func (c ArchiveCache) hasDependencyMapping(sum, platformPath string) (bool, error) {
+	bindings, err := c.bindingResolver.Resolve("dependency-mapping", "", platformPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve 'dependency-mapping' binding: %w", err)
+	}
+
+	for _, binding := range bindings {
+		if _, ok := binding.Entries[sum]; ok {
+			return true, nil
+		}
+
+		if _, ok := binding.Entries[fmt.Sprintf("sha256:%s", sum)]; ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// archiveSHA256 returns the SHA256 of the archive of the dependency, if it is
+// known.
+func archiveSHA256(dependency postal.Dependency) string {
+	if dependency.SHA256 != "" {
+		return dependency.SHA256
+	}
+
+	checksum := cargo.Checksum(dependency.Checksum)
+	if checksum.Algorithm() == "sha256" {
+		return checksum.Hash()
+	}
+
+	return ""
+}
+
+// archiveName returns the file name of the archive at the given URI.
+func archiveName(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse dependency uri: %w", err)
+	}
+
+	name := path.Base(u.Path)
+
+	// file URIs are relative to the buildpack, so url.Parse reads their first
+	// segment as the host
+	if u.Scheme == "file" {
+		name = path.Base(strings.TrimPrefix(uri, "file://"))
+	}
+	if name == "." || name == "/" {
+		return "archive", nil
+	}
+
+	return name, nil
+}
@@ -0,0 +1,239 @@
+package dotnetcoreaspnet_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/dotnet-core-aspnet/fakes"
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testArchiveCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cnbDir          string
+		cacheDir        string
+		dependency      postal.Dependency
+		bindingResolver *fakes.BindingResolver
+		buffer          *bytes.Buffer
+		archiveCache    dotnetcoreaspnet.ArchiveCache
+	)
+
+	it.Before(func() {
+		var err error
+		cnbDir, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		cacheDir, err = os.MkdirTemp("", "download-cache")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(cnbDir, "some-archive.tar.gz"), []byte("some-archive"), 0600)).To(Succeed())
+
+		dependency = postal.Dependency{
+			ID:      "dotnet-aspnetcore",
+			Name:    ".NET Core ASPNet",
+			Version: "6.0.13",
+			URI:     "file://some-archive.tar.gz",
+			SHA256:  "4bd51f0fb046e4b390a4f4e8a85880b287dd1265c8d5e4534399ae10258ccbc4",
+		}
+
+		bindingResolver = &fakes.BindingResolver{}
+		buffer = bytes.NewBuffer(nil)
+		archiveCache = dotnetcoreaspnet.NewArchiveCache(cargo.NewTransport(), scribe.NewEmitter(buffer).WithLevel("DEBUG")).WithBindingResolver(bindingResolver)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cnbDir)).To(Succeed())
+		Expect(os.RemoveAll(cacheDir)).To(Succeed())
+	})
+
+	context("Fetch", func() {
+		it("downloads the archive into the cache and points the dependency at it", func() {
+			cached, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			archive := filepath.Join(cacheDir, dependency.SHA256, "some-archive.tar.gz")
+			Expect(os.ReadFile(archive)).To(Equal([]byte("some-archive")))
+
+			rel, err := filepath.Rel(cnbDir, archive)
+			Expect(err).NotTo(HaveOccurred())
+
+			expected := dependency
+			expected.URI = "file://" + rel
+			Expect(cached).To(Equal(expected))
+
+			Expect(bindingResolver.ResolveCall.Receives.Typ).To(Equal("dependency-mapping"))
+			Expect(bindingResolver.ResolveCall.Receives.PlatformDir).To(Equal("platform"))
+
+			Expect(buffer.String()).To(ContainSubstring("Downloading .NET Core ASPNet 6.0.13 into the download cache"))
+		})
+
+		context("when debug logging is disabled", func() {
+			it.Before(func() {
+				archiveCache = dotnetcoreaspnet.NewArchiveCache(cargo.NewTransport(), scribe.NewEmitter(buffer)).WithBindingResolver(bindingResolver)
+			})
+
+			it("does not log the download", func() {
+				_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+
+		context("when the archive is already cached", func() {
+			it.Before(func() {
+				_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.Remove(filepath.Join(cnbDir, "some-archive.tar.gz"))).To(Succeed())
+				buffer.Reset()
+			})
+
+			it("uses the cached archive", func() {
+				cached, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				bundle, err := cargo.NewTransport().Drop(cnbDir, cached.URI)
+				Expect(err).NotTo(HaveOccurred())
+				defer bundle.Close()
+
+				Expect(buffer.String()).To(ContainSubstring("Using cached archive of .NET Core ASPNet 6.0.13"))
+				Expect(buffer.String()).NotTo(ContainSubstring("Downloading"))
+			})
+		})
+
+		context("when the cached archive is corrupted", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(cacheDir, dependency.SHA256), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(cacheDir, dependency.SHA256, "some-archive.tar.gz"), []byte("some-arch"), 0600)).To(Succeed())
+			})
+
+			it("downloads the archive again", func() {
+				_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.ReadFile(filepath.Join(cacheDir, dependency.SHA256, "some-archive.tar.gz"))).To(Equal([]byte("some-archive")))
+				Expect(buffer.String()).To(ContainSubstring("Downloading .NET Core ASPNet 6.0.13 into the download cache"))
+			})
+		})
+
+		context("when the dependency has no SHA256 checksum", func() {
+			it.Before(func() {
+				dependency.SHA256 = ""
+				dependency.Checksum = "sha512:some-checksum"
+			})
+
+			it("returns the dependency unchanged", func() {
+				cached, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cached).To(Equal(dependency))
+
+				Expect(filepath.Join(cacheDir, "some-checksum")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		context("when the dependency is identified by its checksum", func() {
+			it.Before(func() {
+				dependency.Checksum = "sha256:" + dependency.SHA256
+				dependency.SHA256 = ""
+			})
+
+			it("caches the archive under its SHA256", func() {
+				_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(cacheDir, "4bd51f0fb046e4b390a4f4e8a85880b287dd1265c8d5e4534399ae10258ccbc4", "some-archive.tar.gz")).To(BeARegularFile())
+			})
+		})
+
+		context("when the platform maps the dependency to another location", func() {
+			it.Before(func() {
+				bindingResolver.ResolveCall.Returns.BindingSlice = []servicebindings.Binding{
+					{
+						Name: "some-binding",
+						Type: "dependency-mapping",
+						Entries: map[string]*servicebindings.Entry{
+							dependency.SHA256: servicebindings.NewEntry("some-path"),
+						},
+					},
+				}
+			})
+
+			it("returns the dependency unchanged", func() {
+				cached, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cached).To(Equal(dependency))
+
+				Expect(filepath.Join(cacheDir, dependency.SHA256)).NotTo(BeADirectory())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the checksum of the archive does not match", func() {
+				it.Before(func() {
+					dependency.SHA256 = "some-other-sha"
+				})
+
+				it("returns an error and caches nothing", func() {
+					_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+					Expect(err).To(MatchError("failed to validate dependency: checksum does not match"))
+
+					Expect(os.ReadDir(filepath.Join(cacheDir, "some-other-sha"))).To(BeEmpty())
+				})
+			})
+
+			context("when the archive cannot be fetched", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(cnbDir, "some-archive.tar.gz"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to fetch dependency")))
+				})
+			})
+
+			context("when the dependency mappings cannot be resolved", func() {
+				it.Before(func() {
+					bindingResolver.ResolveCall.Returns.Error = os.ErrPermission
+				})
+
+				it("returns an error", func() {
+					_, err := archiveCache.Fetch(dependency, cnbDir, "platform", cacheDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to resolve 'dependency-mapping' binding")))
+				})
+			})
+		})
+	})
+
+	context("Prune", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(cacheDir, dependency.SHA256), os.ModePerm)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(cacheDir, "some-other-sha"), os.ModePerm)).To(Succeed())
+		})
+
+		it("removes the archives of other dependencies", func() {
+			Expect(archiveCache.Prune(cacheDir, []postal.Dependency{dependency})).To(Succeed())
+
+			Expect(filepath.Join(cacheDir, dependency.SHA256)).To(BeADirectory())
+			Expect(filepath.Join(cacheDir, "some-other-sha")).NotTo(BeAnExistingFile())
+		})
+
+		context("when the cache directory does not exist", func() {
+			it("succeeds", func() {
+				Expect(archiveCache.Prune(filepath.Join(cacheDir, "missing"), nil)).To(Succeed())
+			})
+		})
+	})
+}
@@ -1,9 +1,11 @@
 package dotnetcoreaspnet
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
@@ -27,20 +29,52 @@ type DependencyManager interface {
 	GenerateBillOfMaterials(dependencies ...postal.Dependency) []packit.BOMEntry
 }
 
+//go:generate faux --interface DownloadCache --output fakes/download_cache.go
+type DownloadCache interface {
+	Fetch(dependency postal.Dependency, cnbPath, platformPath, cacheDir string) (postal.Dependency, error)
+	Prune(cacheDir string, keep []postal.Dependency) error
+}
+
+//go:generate faux --interface Catalog --output fakes/catalog.go
+type Catalog interface {
+	Dependencies(path, id string) ([]CatalogDependency, error)
+	DefaultVersion(path, id string) (string, error)
+}
+
+//go:generate faux --interface AppAnalyzer --output fakes/app_analyzer.go
+type AppAnalyzer interface {
+	Analyze(workingDir string) (AppAnalysis, error)
+}
+
 //go:generate faux --interface Symlinker --output fakes/symlinker.go
 type Symlinker interface {
-	Link(workingDir, layerPath string) (Err error)
+	Link(dotnetRoot, layerPath string) (Err error)
+}
+
+//go:generate faux --interface CompatibilityValidator --output fakes/compatibility_validator.go
+type CompatibilityValidator interface {
+	Validate(layerPath string, dotnetRoots []string) error
+}
+
+//go:generate faux --interface InstallationVerifier --output fakes/installation_verifier.go
+type InstallationVerifier interface {
+	Verify(layerPath, version string) error
 }
 
 //go:generate faux --interface SBOMGenerator --output fakes/sbom_generator.go
 type SBOMGenerator interface {
-	GenerateFromDependency(dependency postal.Dependency, dir string) (sbom.SBOM, error)
+	GenerateFromDependencies(dependencies []postal.Dependency, dir string) (sbom.SBOM, error)
 }
 
 func Build(
 	entries EntryResolver,
 	dependencies DependencyManager,
+	downloads DownloadCache,
+	catalog Catalog,
+	analyzer AppAnalyzer,
 	symlinker Symlinker,
+	validator CompatibilityValidator,
+	verifier InstallationVerifier,
 	sbomGenerator SBOMGenerator,
 	logger scribe.Emitter,
 	clock chronos.Clock,
@@ -49,18 +83,57 @@ func Build(
 		logger.Title("%s %s", context.BuildpackInfo.Name, context.BuildpackInfo.Version)
 		logger.Process("Resolving .NET Core ASPNet version")
 
+		ignoreRuntimeVersion, err := boolEnv("BP_DOTNET_IGNORE_RUNTIME_VERSION")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
 		if v, ok := os.LookupEnv("RUNTIME_VERSION"); ok {
-			context.Plan.Entries = append(context.Plan.Entries, packit.BuildpackPlanEntry{
-				Name: "dotnet-aspnetcore",
-				Metadata: map[string]interface{}{
-					"version":        v,
-					"version-source": "RUNTIME_VERSION",
-				},
-			})
+			exported, err := runtimeVersionExported(context.Layers.Path, v)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			switch {
+			case exported:
+				// the version of the installed runtime is always honored, so
+				// that the framework matches it
+				logger.Debug.Subprocess("RUNTIME_VERSION=%s was set by the .NET Core Runtime buildpack", v)
+			case ignoreRuntimeVersion:
+				logger.Subprocess("Ignoring RUNTIME_VERSION=%s because BP_DOTNET_IGNORE_RUNTIME_VERSION is set.", v)
+				logger.Break()
+			default:
+				logger.Subprocess("WARNING: Setting the .NET Framework version through RUNTIME_VERSION is deprecated and will be removed in a future version of the .NET Core ASPNet Buildpack.")
+				logger.Subprocess("RUNTIME_VERSION overrides every other version source. Please specify the version through the $BP_DOTNET_FRAMEWORK_VERSION environment variable instead:")
+				logger.Action("BP_DOTNET_FRAMEWORK_VERSION=%s", v)
+				logger.Subprocess("Set BP_DOTNET_IGNORE_RUNTIME_VERSION=true to ignore RUNTIME_VERSION.")
+				logger.Break()
+			}
+
+			if exported || !ignoreRuntimeVersion {
+				context.Plan.Entries = append(context.Plan.Entries, packit.BuildpackPlanEntry{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version":        v,
+						"version-source": "RUNTIME_VERSION",
+					},
+				})
+			}
+		}
+
+		strategy, err := dotnetRootStrategy()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		verification, err := cacheVerificationMode()
+		if err != nil {
+			return packit.BuildResult{}, err
 		}
 
 		priorities := []interface{}{
 			"RUNTIME_VERSION",
+			"BP_DOTNET_ASPNETCORE_VERSIONS",
 			"BP_DOTNET_FRAMEWORK_VERSION",
 			"buildpack.yml",
 			regexp.MustCompile(`.*\.(cs)|(fs)|(vb)proj`),
@@ -70,8 +143,6 @@ func Build(
 		entry, sortedEntries := entries.Resolve("dotnet-aspnetcore", context.Plan.Entries, priorities)
 		logger.Candidates(sortedEntries)
 
-		version, _ := entry.Metadata["version"].(string)
-
 		source, _ := entry.Metadata["version-source"].(string)
 		if source == "buildpack.yml" {
 			nextMajorVersion := semver.MustParse(context.BuildpackInfo.Version).IncMajor()
@@ -80,19 +151,197 @@ func Build(
 			logger.Break()
 		}
 
-		dependency, err := dependencies.Resolve(filepath.Join(context.CNBPath, "buildpack.toml"), entry.Name, version, context.Stack)
+		strict, err := boolEnv("BP_DOTNET_STRICT_VERSION")
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		logger.SelectedDependency(entry, dependency, clock.Now())
+		selectedEntries := entriesWithSource(entry, context.Plan.Entries)
+		if conflicts := VersionConflicts(selectedEntries, context.Plan.Entries); len(conflicts) > 0 {
+			var requests []string
+			for _, conflict := range conflicts {
+				requests = append(requests, conflict.String())
+			}
+
+			if strict {
+				return packit.BuildResult{}, fmt.Errorf("failed to satisfy version requests: %s requests %s, but %s", source, describeVersions(selectedEntries), strings.Join(requests, ", "))
+			}
+
+			logger.Subprocess("WARNING: The version requests for .NET Core ASPNet disagree on the major.minor version:")
+			logger.Action("%s requests %s (selected)", source, describeVersions(selectedEntries))
+			for _, request := range requests {
+				logger.Action("%s", request)
+			}
+			logger.Subprocess("The application may fail to start with the selected version.")
+			logger.Subprocess("Set BP_DOTNET_STRICT_VERSION=true to fail the build on conflicting version requests.")
+			logger.Break()
+		}
+
+		autoUpgrade, err := boolEnv("BP_DOTNET_AUTO_UPGRADE_PATCHES")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		allowPrerelease, err := boolEnv("BP_DOTNET_ALLOW_PRERELEASE")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		buildpackTOMLPath := filepath.Join(context.CNBPath, "buildpack.toml")
+		allDeps, err := catalog.Dependencies(buildpackTOMLPath, "dotnet-aspnetcore")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		defaultVersion, err := catalog.DefaultVersion(buildpackTOMLPath, "dotnet-aspnetcore")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		target := NewTarget(context.Stack)
+		var platformDeps, catalogDeps []CatalogDependency
+		for _, dependency := range allDeps {
+			if dependency.SupportsPlatform(target) {
+				platformDeps = append(platformDeps, dependency)
+				if dependency.SupportsArch(target.Arch) {
+					catalogDeps = append(catalogDeps, dependency)
+				}
+			}
+		}
+
+		// the dependency manager only matches dependencies on the stack, so
+		// platforms that describe their distribution are resolved against the
+		// catalog instead
+		resolve := func(id, version string) (postal.Dependency, error) {
+			if target.HasDistro() {
+				return resolveFromCatalog(catalogDeps, id, version, defaultVersion, target)
+			}
+
+			dependency, err := dependencies.Resolve(buildpackTOMLPath, id, version, context.Stack)
+			if err != nil {
+				return postal.Dependency{}, err
+			}
+
+			// the dependency manager does not match dependencies on the
+			// architecture either, so when the version it selected is not built
+			// for the target, the latest version that is takes its place
+			if _, err := selectArch(platformDeps, dependency, target.Arch); err == nil {
+				return dependency, nil
+			}
+
+			latest, err := resolveFromCatalog(catalogDeps, id, version, defaultVersion, target)
+			if err != nil {
+				return dependency, nil
+			}
+
+			return dependencies.Resolve(buildpackTOMLPath, id, latest.Version, context.Stack)
+		}
+
+		// every distinct version requested through the selected version source
+		// is installed side-by-side
+		var deps []postal.Dependency
+		for _, versionEntry := range selectedEntries {
+			dependency, err := resolveDependency(resolve, catalogDeps, versionEntry, allowPrerelease, logger)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if patches := newerPatches(catalogDeps, dependency.Version, allowPrerelease); len(patches) > 0 {
+				version, _ := versionEntry.Metadata["version"].(string)
+				latest := patches[len(patches)-1]
+
+				// the version requested through RUNTIME_VERSION is that of the
+				// installed runtime, which a later ASP.NET Core patch would require
+				// to be upgraded as well
+				pinned := exactVersionPattern.MatchString(version)
+				runtimePin := pinned && versionEntry.Metadata["version-source"] == "RUNTIME_VERSION"
+
+				if autoUpgrade && pinned && !runtimePin {
+					logger.Subprocess("Upgrading pinned version %s to the latest patch %s", version, latest.Version)
+					logger.Break()
+
+					dependency, err = resolve(versionEntry.Name, latest.Version)
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+				} else {
+					var notices []string
+					for _, patch := range patches {
+						notice := patch.Version
+						if patch.Security {
+							notice += " (security)"
+						}
+						notices = append(notices, notice)
+					}
+
+					logger.Subprocess("Newer patches of %s %s are available: %s", dependency.Name, dependency.Version, strings.Join(notices, ", "))
+					switch {
+					case runtimePin && autoUpgrade:
+						logger.Subprocess("Keeping %s, which matches the installed .NET Core Runtime version in RUNTIME_VERSION.", version)
+					case pinned && !runtimePin:
+						logger.Subprocess("Set BP_DOTNET_AUTO_UPGRADE_PATCHES=true to upgrade pinned versions to the latest patch.")
+					}
+					logger.Break()
+				}
+			}
+
+			dependency, err = selectArch(platformDeps, dependency, target.Arch)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if containsDependency(deps, dependency) {
+				continue
+			}
+
+			logger.SelectedDependency(withAliasSource(versionEntry), dependency, clock.Now())
+			if isPrerelease(dependency.Version) {
+				logger.Subprocess("WARNING: %s %s is a prerelease version and is not supported for production use.", dependency.Name, dependency.Version)
+				logger.Break()
+			}
+
+			deps = append(deps, dependency)
+		}
+
+		err = checkEndOfLife(deps, clock.Now(), logger)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		analysis, err := analyzer.Analyze(context.WorkingDir)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		if analysis.UsesASPNET {
+			logger.Debug.Subprocess("The application needs ASP.NET Core: %s", analysis.Reason)
+		} else {
+			logger.Subprocess("WARNING: Installing .NET Core ASPNet for an application that shows no sign of needing it: %s.", analysis.Reason)
+			logger.Subprocess("Another buildpack may be requiring dotnet-aspnetcore unnecessarily.")
+			logger.Break()
+		}
 
 		aspNetLayer, err := context.Layers.Get("dotnet-core-aspnet")
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		bom := dependencies.GenerateBillOfMaterials(dependency)
+		// the downloaded archives are kept in a layer of their own, so that the
+		// framework can be installed again without downloading it whenever the
+		// install layer is rebuilt
+		downloadLayer, err := context.Layers.Get("download-cache")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		downloadLayer.Cache = true
+
+		err = downloads.Prune(downloadLayer.Path, deps)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		bom := dependencies.GenerateBillOfMaterials(deps...)
 		launch, build := entries.MergeLayerTypes("dotnet-aspnetcore", context.Plan.Entries)
 
 		var buildMetadata packit.BuildMetadata
@@ -105,12 +354,61 @@ func Build(
 			launchMetadata.BOM = bom
 		}
 
-		cachedSHA, ok := aspNetLayer.Metadata["dependency-sha"].(string)
-		if ok && cachedSHA == dependency.SHA256 {
+		shas := map[string]interface{}{}
+		for _, dependency := range deps {
+			shas[dependency.Version] = dependency.SHA256
+		}
+
+		// the layer is only reused when it was built from the same dependencies
+		// under the same conditions
+		cacheKey := map[string]interface{}{
+			"buildpack-version":     context.BuildpackInfo.Version,
+			"target":                target.String(),
+			"dotnet-root-strategy":  strategy,
+			"dotnet-root-link-mode": linkMode(),
+		}
+
+		reusable := len(aspNetLayer.Metadata) > 0
+		if reusable {
+			if changes := cacheKeyChanges(aspNetLayer.Metadata, shas, cacheKey); len(changes) > 0 {
+				logger.Process("Not reusing cached layer %s", aspNetLayer.Path)
+				for _, change := range changes {
+					logger.Subprocess(change)
+				}
+				logger.Break()
+				reusable = false
+			}
+		}
+
+		if reusable {
+			err = verifyCachedLayer(aspNetLayer, verification)
+			if err != nil {
+				logger.Process("Cached layer %s failed verification", aspNetLayer.Path)
+				logger.Subprocess(err.Error())
+				logger.Break()
+				reusable = false
+			}
+		}
+
+		if reusable {
 			logger.Process("Reusing cached layer %s", aspNetLayer.Path)
 			logger.Break()
 
-			err = symlinker.Link(context.WorkingDir, aspNetLayer.Path)
+			dotnetRoot, rootLayers, err := linkDotnetRoot(context, symlinker, strategy, aspNetLayer.Path, launch, build)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if len(rootLayers) == 0 {
+				setDotnetRoot(&aspNetLayer, dotnetRoot, launch, build)
+			}
+
+			err = publishFrameworks(&aspNetLayer, deps)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			err = validator.Validate(aspNetLayer.Path, dotnetRoots(context.WorkingDir))
 			if err != nil {
 				return packit.BuildResult{}, err
 			}
@@ -118,7 +416,7 @@ func Build(
 			aspNetLayer.Launch, aspNetLayer.Build, aspNetLayer.Cache = launch, build, launch || build
 
 			return packit.BuildResult{
-				Layers: []packit.Layer{aspNetLayer},
+				Layers: append(append([]packit.Layer{aspNetLayer}, rootLayers...), downloadLayer),
 				Build:  buildMetadata,
 				Launch: launchMetadata,
 			}, nil
@@ -132,9 +430,26 @@ func Build(
 
 		aspNetLayer.Launch, aspNetLayer.Build, aspNetLayer.Cache = launch, build, launch || build
 
-		logger.Subprocess("Installing .NET Core ASPNet %s", dependency.Version)
 		duration, err := clock.Measure(func() error {
-			return dependencies.Deliver(dependency, context.CNBPath, aspNetLayer.Path, context.Platform.Path)
+			for _, dependency := range deps {
+				logger.Subprocess("Installing .NET Core ASPNet %s", dependency.Version)
+				archive, err := downloads.Fetch(dependency, context.CNBPath, context.Platform.Path, downloadLayer.Path)
+				if err != nil {
+					return err
+				}
+
+				err = dependencies.Deliver(archive, context.CNBPath, aspNetLayer.Path, context.Platform.Path)
+				if err != nil {
+					return err
+				}
+
+				err = verifier.Verify(aspNetLayer.Path, dependency.Version)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
 		})
 		if err != nil {
 			return packit.BuildResult{}, err
@@ -143,14 +458,37 @@ func Build(
 		logger.Action("Completed in %s", duration.Round(time.Millisecond))
 		logger.Break()
 
+		manifest, err := NewContentManifest(aspNetLayer.Path)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
 		aspNetLayer.Metadata = map[string]interface{}{
-			"dependency-sha": dependency.SHA256,
+			"dependency-shas":  shas,
+			"cache-key":        cacheKey,
+			"content-manifest": manifest,
+		}
+
+		dotnetRoot, rootLayers, err := linkDotnetRoot(context, symlinker, strategy, aspNetLayer.Path, launch, build)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		err = publishFrameworks(&aspNetLayer, deps)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		if len(rootLayers) == 0 {
+			setDotnetRoot(&aspNetLayer, dotnetRoot, launch, build)
 		}
 
-		aspNetLayer.LaunchEnv.Override("DOTNET_ROOT", filepath.Join(context.WorkingDir, ".dotnet_root"))
 		logger.EnvironmentVariables(aspNetLayer)
+		if len(rootLayers) > 0 {
+			logger.EnvironmentVariables(rootLayers[0])
+		}
 
-		err = symlinker.Link(context.WorkingDir, aspNetLayer.Path)
+		err = validator.Validate(aspNetLayer.Path, dotnetRoots(context.WorkingDir))
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
@@ -158,7 +496,7 @@ func Build(
 		logger.GeneratingSBOM(aspNetLayer.Path)
 		var sbomContent sbom.SBOM
 		duration, err = clock.Measure(func() error {
-			sbomContent, err = sbomGenerator.GenerateFromDependency(dependency, aspNetLayer.Path)
+			sbomContent, err = sbomGenerator.GenerateFromDependencies(deps, aspNetLayer.Path)
 			return err
 		})
 		if err != nil {
@@ -175,9 +513,43 @@ func Build(
 		}
 
 		return packit.BuildResult{
-			Layers: []packit.Layer{aspNetLayer},
+			Layers: append(append([]packit.Layer{aspNetLayer}, rootLayers...), downloadLayer),
 			Build:  buildMetadata,
 			Launch: launchMetadata,
 		}, nil
 	}
 }
+
+// entriesWithSource returns the selected entry followed by every other entry
+// that requests a different version through the same version source.
+func entriesWithSource(selected packit.BuildpackPlanEntry, entries []packit.BuildpackPlanEntry) []packit.BuildpackPlanEntry {
+	result := []packit.BuildpackPlanEntry{selected}
+	for _, entry := range entries {
+		if entry.Name != selected.Name || entry.Metadata["version-source"] != selected.Metadata["version-source"] {
+			continue
+		}
+
+		duplicate := false
+		for _, e := range result {
+			if e.Metadata["version"] == entry.Metadata["version"] {
+				duplicate = true
+			}
+		}
+
+		if !duplicate {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+func containsDependency(dependencies []postal.Dependency, dependency postal.Dependency) bool {
+	for _, d := range dependencies {
+		if d.Version == dependency.Version && d.SHA256 == dependency.SHA256 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,14 +3,19 @@ package dotnetcoreaspnet_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
 	"github.com/paketo-buildpacks/dotnet-core-aspnet/fakes"
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/paketo-buildpacks/packit/v2/draft"
 	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
 
@@ -31,7 +36,13 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		cnbDir            string
 		entryResolver     *fakes.EntryResolver
 		dependencyManager *fakes.DependencyManager
+		downloadCache     *fakes.DownloadCache
+		cacheKey          map[string]interface{}
+		catalog           *fakes.Catalog
+		analyzer          *fakes.AppAnalyzer
 		symlinker         *fakes.Symlinker
+		validator         *fakes.CompatibilityValidator
+		verifier          *fakes.InstallationVerifier
 		sbomGenerator     *fakes.SBOMGenerator
 		buffer            *bytes.Buffer
 
@@ -50,6 +61,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(err).NotTo(HaveOccurred())
 
 		entryResolver = &fakes.EntryResolver{}
+		entryResolver.MergeLayerTypesCall.Returns.Launch = true
 		entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
 			Name: "dotnet-aspnetcore",
 			Metadata: map[string]interface{}{
@@ -60,8 +72,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		dependencyManager = &fakes.DependencyManager{}
 		dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
-			ID:   "dotnet-aspnetcore",
-			Name: ".NET Core ASPNet",
+			ID:      "dotnet-aspnetcore",
+			Name:    ".NET Core ASPNet",
+			Version: "2.5.1",
 		}
 		dependencyManager.GenerateBillOfMaterialsCall.Returns.BOMEntrySlice = []packit.BOMEntry{
 			{
@@ -77,14 +90,34 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			},
 		}
 
+		cacheKey = map[string]interface{}{
+			"buildpack-version":     "some-version",
+			"target":                fmt.Sprintf("some-stack (%s/%s)", runtime.GOOS, runtime.GOARCH),
+			"dotnet-root-strategy":  "working-dir",
+			"dotnet-root-link-mode": "framework",
+		}
+
+		downloadCache = &fakes.DownloadCache{}
+		downloadCache.FetchCall.Stub = func(dependency postal.Dependency, _, _, _ string) (postal.Dependency, error) {
+			return dependency, nil
+		}
+
+		catalog = &fakes.Catalog{}
+		analyzer = &fakes.AppAnalyzer{}
+		analyzer.AnalyzeCall.Returns.AppAnalysis = dotnetcoreaspnet.AppAnalysis{
+			UsesASPNET: true,
+			Reason:     "some-app.runtimeconfig.json references the Microsoft.AspNetCore.App framework",
+		}
 		symlinker = &fakes.Symlinker{}
+		validator = &fakes.CompatibilityValidator{}
+		verifier = &fakes.InstallationVerifier{}
 
 		sbomGenerator = &fakes.SBOMGenerator{}
-		sbomGenerator.GenerateFromDependencyCall.Returns.SBOM = sbom.SBOM{}
+		sbomGenerator.GenerateFromDependenciesCall.Returns.SBOM = sbom.SBOM{}
 
 		buffer = bytes.NewBuffer(nil)
 
-		build = dotnetcoreaspnet.Build(entryResolver, dependencyManager, symlinker, sbomGenerator, scribe.NewEmitter(buffer), chronos.DefaultClock)
+		build = dotnetcoreaspnet.Build(entryResolver, dependencyManager, downloadCache, catalog, analyzer, symlinker, validator, verifier, sbomGenerator, scribe.NewEmitter(buffer), chronos.DefaultClock)
 	})
 
 	it.After(func() {
@@ -119,18 +152,37 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(result.Layers).To(HaveLen(1))
+		Expect(result.Layers).To(HaveLen(2))
 		layer := result.Layers[0]
 
+		downloadLayer := result.Layers[1]
+		Expect(downloadLayer.Name).To(Equal("download-cache"))
+		Expect(downloadLayer.Path).To(Equal(filepath.Join(layersDir, "download-cache")))
+		Expect(downloadLayer.Build).To(BeFalse())
+		Expect(downloadLayer.Launch).To(BeFalse())
+		Expect(downloadLayer.Cache).To(BeTrue())
+
 		Expect(layer.Name).To(Equal("dotnet-core-aspnet"))
 		Expect(layer.Path).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
 		Expect(layer.LaunchEnv).To(Equal(packit.Environment{
 			"DOTNET_ROOT.override": filepath.Join(workingDir, ".dotnet_root"),
 		}))
+		Expect(layer.SharedEnv).To(Equal(packit.Environment{
+			"DOTNET_ASPNETCORE_VERSION.override": "2.5.1",
+			"DOTNET_ASPNETCORE_PATH.override":    filepath.Join(layersDir, "dotnet-core-aspnet", "shared", "Microsoft.AspNetCore.App"),
+		}))
 		Expect(layer.Metadata).To(Equal(map[string]interface{}{
-			"dependency-sha": "",
+			"dependency-shas":  map[string]interface{}{"2.5.1": ""},
+			"content-manifest": dotnetcoreaspnet.ContentManifest{},
+			"cache-key":        cacheKey,
 		}))
 
+		content, err := os.ReadFile(filepath.Join(layersDir, "dotnet-core-aspnet", "aspnetcore.toml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring(`name = "Microsoft.AspNetCore.App"`))
+		Expect(string(content)).To(ContainSubstring(`version = "2.5.1"`))
+		Expect(string(content)).To(ContainSubstring(fmt.Sprintf(`path = %q`, filepath.Join(layersDir, "dotnet-core-aspnet", "shared", "Microsoft.AspNetCore.App", "2.5.1"))))
+
 		Expect(layer.SBOM.Formats()).To(Equal([]packit.SBOMFormat{
 			{
 				Extension: sbom.Format(sbom.CycloneDXFormat).Extension(),
@@ -149,25 +201,50 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		Expect(dependencyManager.GenerateBillOfMaterialsCall.Receives.Dependencies).To(Equal([]postal.Dependency{
 			{
-				ID:   "dotnet-aspnetcore",
-				Name: ".NET Core ASPNet",
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "2.5.1",
+			},
+		}))
+
+		Expect(downloadCache.PruneCall.Receives.CacheDir).To(Equal(filepath.Join(layersDir, "download-cache")))
+		Expect(downloadCache.PruneCall.Receives.Keep).To(Equal([]postal.Dependency{
+			{
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "2.5.1",
 			},
 		}))
 
-		Expect(dependencyManager.DeliverCall.Receives.Dependency).To(Equal(postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet"}))
+		Expect(downloadCache.FetchCall.Receives.Dependency).To(Equal(postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "2.5.1"}))
+		Expect(downloadCache.FetchCall.Receives.CnbPath).To(Equal(cnbDir))
+		Expect(downloadCache.FetchCall.Receives.PlatformPath).To(Equal("platform"))
+		Expect(downloadCache.FetchCall.Receives.CacheDir).To(Equal(filepath.Join(layersDir, "download-cache")))
+
+		Expect(dependencyManager.DeliverCall.Receives.Dependency).To(Equal(postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "2.5.1"}))
 		Expect(dependencyManager.DeliverCall.Receives.CnbPath).To(Equal(cnbDir))
 		Expect(dependencyManager.DeliverCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
 		Expect(dependencyManager.DeliverCall.Receives.PlatformPath).To(Equal("platform"))
 
+		Expect(verifier.VerifyCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+		Expect(verifier.VerifyCall.Receives.Version).To(Equal("2.5.1"))
+
 		Expect(symlinker.LinkCall.CallCount).To(Equal(1))
-		Expect(symlinker.LinkCall.Receives.WorkingDir).To(Equal(workingDir))
+		Expect(symlinker.LinkCall.Receives.DotnetRoot).To(Equal(filepath.Join(workingDir, ".dotnet_root")))
 		Expect(symlinker.LinkCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
 
-		Expect(sbomGenerator.GenerateFromDependencyCall.Receives.Dependency).To(Equal(postal.Dependency{
-			ID:   "dotnet-aspnetcore",
-			Name: ".NET Core ASPNet",
+		Expect(validator.ValidateCall.CallCount).To(Equal(1))
+		Expect(validator.ValidateCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+		Expect(validator.ValidateCall.Receives.DotnetRoots).To(ContainElement(filepath.Join(workingDir, ".dotnet_root")))
+
+		Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dependencies).To(Equal([]postal.Dependency{
+			{
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "2.5.1",
+			},
 		}))
-		Expect(sbomGenerator.GenerateFromDependencyCall.Receives.Dir).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+		Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dir).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
 	})
 
 	context("when the 'RUNTIME_VERSION' env variable is set", func() {
@@ -203,7 +280,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(1))
+			Expect(result.Layers).To(HaveLen(2))
 			layer := result.Layers[0]
 
 			Expect(layer.Name).To(Equal("dotnet-core-aspnet"))
@@ -212,7 +289,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				"DOTNET_ROOT.override": filepath.Join(workingDir, ".dotnet_root"),
 			}))
 			Expect(layer.Metadata).To(Equal(map[string]interface{}{
-				"dependency-sha": "",
+				"dependency-shas":  map[string]interface{}{"2.5.1": ""},
+				"content-manifest": dotnetcoreaspnet.ContentManifest{},
+				"cache-key":        cacheKey,
 			}))
 
 			Expect(entryResolver.ResolveCall.Receives.BuildpackPlanEntrySlice).To(ContainElement(packit.BuildpackPlanEntry{
@@ -222,252 +301,2187 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					"version-source": "RUNTIME_VERSION",
 				},
 			}))
+
+			Expect(buffer.String()).To(ContainSubstring("WARNING: Setting the .NET Framework version through RUNTIME_VERSION is deprecated and will be removed in a future version of the .NET Core ASPNet Buildpack."))
+			Expect(buffer.String()).To(ContainSubstring("RUNTIME_VERSION overrides every other version source. Please specify the version through the $BP_DOTNET_FRAMEWORK_VERSION environment variable instead:"))
+			Expect(buffer.String()).To(ContainSubstring("BP_DOTNET_FRAMEWORK_VERSION=some-version"))
+			Expect(buffer.String()).To(ContainSubstring("Set BP_DOTNET_IGNORE_RUNTIME_VERSION=true to ignore RUNTIME_VERSION."))
 		})
-	})
 
-	context("when the build plan entry include build, launch flags", func() {
-		it.Before(func() {
-			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
-				Name: "dotnet-aspnetcore",
-				Metadata: map[string]interface{}{
-					"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
-					"version":        "2.5.x",
-					"build":          true,
-					"launch":         true,
-				},
-			}
+		context("when BP_DOTNET_IGNORE_RUNTIME_VERSION is true", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_IGNORE_RUNTIME_VERSION", "true")).To(Succeed())
+			})
 
-			entryResolver.MergeLayerTypesCall.Returns.Launch = true
-			entryResolver.MergeLayerTypesCall.Returns.Build = true
-		})
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_IGNORE_RUNTIME_VERSION")).To(Succeed())
+			})
 
-		it("marks the layer as build, cache and launch", func() {
-			result, err := build(packit.BuildContext{
-				WorkingDir: workingDir,
-				CNBPath:    cnbDir,
-				Stack:      "some-stack",
-				BuildpackInfo: packit.BuildpackInfo{
-					Name:    "Some Buildpack",
-					Version: "some-version",
-				},
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{
-						{
-							Name: "dotnet-aspnetcore",
-							Metadata: map[string]interface{}{
-								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
-								"version":        "2.5.x",
-								"build":          true,
-								"launch":         true,
+			it("ignores the variable", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{
+								Name: "dotnet-aspnetcore",
+								Metadata: map[string]interface{}{
+									"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+									"version":        "2.5.x",
+								},
 							},
 						},
 					},
-				},
-				Layers: packit.Layers{Path: layersDir},
-			})
-			Expect(err).NotTo(HaveOccurred())
-
-			Expect(result.Layers).To(HaveLen(1))
-			layer := result.Layers[0]
-
-			Expect(layer.Name).To(Equal("dotnet-core-aspnet"))
-			Expect(layer.Path).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
-			Expect(layer.LaunchEnv).To(Equal(packit.Environment{
-				"DOTNET_ROOT.override": filepath.Join(workingDir, ".dotnet_root"),
-			}))
-			Expect(layer.Metadata).To(Equal(map[string]interface{}{
-				"dependency-sha": "",
-			}))
-
-			Expect(layer.Build).To(BeTrue())
-			Expect(layer.Launch).To(BeTrue())
-			Expect(layer.Cache).To(BeTrue())
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Build.BOM).To(HaveLen(1))
-			buildBOMEntry := result.Build.BOM[0]
-			Expect(buildBOMEntry.Name).To(Equal("dotnet-aspnetcore"))
-			Expect(buildBOMEntry.Metadata).To(Equal(paketosbom.BOMMetadata{
-				Version: "dotnet-aspnetcore-dep-version",
-				Checksum: paketosbom.BOMChecksum{
-					Algorithm: paketosbom.SHA256,
-					Hash:      "dotnet-aspnetcore-dep-sha",
-				},
-				URI: "dotnet-aspnetcore-dep-uri",
-			}))
+				Expect(entryResolver.ResolveCall.Receives.BuildpackPlanEntrySlice).To(Equal([]packit.BuildpackPlanEntry{
+					{
+						Name: "dotnet-aspnetcore",
+						Metadata: map[string]interface{}{
+							"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+							"version":        "2.5.x",
+						},
+					},
+				}))
 
-			Expect(result.Launch.BOM).To(HaveLen(1))
-			launchBOMEntry := result.Launch.BOM[0]
-			Expect(launchBOMEntry.Name).To(Equal("dotnet-aspnetcore"))
-			Expect(launchBOMEntry.Metadata).To(Equal(paketosbom.BOMMetadata{
-				Version: "dotnet-aspnetcore-dep-version",
-				Checksum: paketosbom.BOMChecksum{
-					Algorithm: paketosbom.SHA256,
-					Hash:      "dotnet-aspnetcore-dep-sha",
-				},
-				URI: "dotnet-aspnetcore-dep-uri",
-			}))
+				Expect(buffer.String()).To(ContainSubstring("Ignoring RUNTIME_VERSION=some-version because BP_DOTNET_IGNORE_RUNTIME_VERSION is set."))
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING: Setting the .NET Framework version through RUNTIME_VERSION"))
+			})
 		})
-	})
-
-	context("when there is a dependency cache match", func() {
-		it.Before(func() {
-			err := os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet.toml"), []byte("[metadata]\ndependency-sha = \"some-sha\"\n"), 0600)
-			Expect(err).NotTo(HaveOccurred())
 
-			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
-				ID:     "dotnet-aspnetcore",
-				Name:   ".NET Core ASPNet",
-				SHA256: "some-sha",
-			}
-			entryResolver.MergeLayerTypesCall.Returns.Launch = true
-			entryResolver.MergeLayerTypesCall.Returns.Build = false
-		})
+		context("when the variable was set by the .NET Core Runtime buildpack", func() {
+			var buildContext packit.BuildContext
 
-		it("exits build process early", func() {
-			result, err := build(packit.BuildContext{
-				WorkingDir: workingDir,
-				CNBPath:    cnbDir,
-				Stack:      "some-stack",
-				BuildpackInfo: packit.BuildpackInfo{
-					Name:    "Some Buildpack",
-					Version: "some-version",
-				},
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{
-						{
-							Name: "dotnet-aspnetcore",
-							Metadata: map[string]interface{}{
-								"version":        "2.5.x",
-								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+			it.Before(func() {
+				runtimeEnv := filepath.Join(layersDir, "paketo-buildpacks_dotnet-core-runtime", "dotnet-core-runtime", "env")
+				Expect(os.MkdirAll(runtimeEnv, os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(runtimeEnv, "RUNTIME_VERSION.override"), []byte("some-version"), 0600)).To(Succeed())
+
+				buildContext = packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{
+								Name: "dotnet-aspnetcore",
+								Metadata: map[string]interface{}{
+									"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+									"version":        "2.5.x",
+								},
 							},
 						},
 					},
-				},
-				Layers: packit.Layers{Path: layersDir},
+					Layers: packit.Layers{Path: filepath.Join(layersDir, "paketo-buildpacks_dotnet-core-aspnet")},
+				}
 			})
-			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(1))
-			layer := result.Layers[0]
+			it("requests the version of the installed runtime without a deprecation warning", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
 
-			Expect(layer.Name).To(Equal("dotnet-core-aspnet"))
-			Expect(layer.Path).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
-			Expect(layer.Metadata).To(Equal(map[string]interface{}{
-				"dependency-sha": "some-sha",
-			}))
+				Expect(entryResolver.ResolveCall.Receives.BuildpackPlanEntrySlice).To(ContainElement(packit.BuildpackPlanEntry{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version":        "some-version",
+						"version-source": "RUNTIME_VERSION",
+					},
+				}))
 
-			Expect(layer.Build).To(BeFalse())
-			Expect(layer.Launch).To(BeTrue())
-			Expect(layer.Cache).To(BeTrue())
+				Expect(buffer.String()).NotTo(ContainSubstring("RUNTIME_VERSION is deprecated"))
+				Expect(buffer.String()).NotTo(ContainSubstring("BP_DOTNET_IGNORE_RUNTIME_VERSION"))
+			})
 
-			Expect(result.Launch.BOM).To(HaveLen(1))
-			launchBOMEntry := result.Launch.BOM[0]
-			Expect(launchBOMEntry.Name).To(Equal("dotnet-aspnetcore"))
-			Expect(launchBOMEntry.Metadata).To(Equal(paketosbom.BOMMetadata{
-				Version: "dotnet-aspnetcore-dep-version",
-				Checksum: paketosbom.BOMChecksum{
-					Algorithm: paketosbom.SHA256,
-					Hash:      "dotnet-aspnetcore-dep-sha",
-				},
-				URI: "dotnet-aspnetcore-dep-uri",
-			}))
+			context("when BP_DOTNET_IGNORE_RUNTIME_VERSION is true", func() {
+				it.Before(func() {
+					Expect(os.Setenv("BP_DOTNET_IGNORE_RUNTIME_VERSION", "true")).To(Succeed())
+				})
 
-			Expect(dependencyManager.GenerateBillOfMaterialsCall.Receives.Dependencies).To(Equal([]postal.Dependency{
-				{
-					ID:     "dotnet-aspnetcore",
-					Name:   ".NET Core ASPNet",
-					SHA256: "some-sha",
-				},
-			}))
+				it.After(func() {
+					Expect(os.Unsetenv("BP_DOTNET_IGNORE_RUNTIME_VERSION")).To(Succeed())
+				})
 
-			Expect(symlinker.LinkCall.CallCount).To(Equal(1))
-			Expect(symlinker.LinkCall.Receives.WorkingDir).To(Equal(workingDir))
-			Expect(symlinker.LinkCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+				it("still requests the version of the installed runtime", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
 
-			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+					Expect(entryResolver.ResolveCall.Receives.BuildpackPlanEntrySlice).To(ContainElement(packit.BuildpackPlanEntry{
+						Name: "dotnet-aspnetcore",
+						Metadata: map[string]interface{}{
+							"version":        "some-version",
+							"version-source": "RUNTIME_VERSION",
+						},
+					}))
 
-			Expect(buffer.String()).To(ContainSubstring("Some Buildpack some-version"))
-			Expect(buffer.String()).To(ContainSubstring("Resolving .NET Core ASPNet version"))
-			Expect(buffer.String()).To(ContainSubstring("Selected .NET Core ASPNet version (using BP_DOTNET_FRAMEWORK_VERSION): "))
-			Expect(buffer.String()).To(ContainSubstring("Reusing cached layer"))
-			Expect(buffer.String()).ToNot(ContainSubstring("Executing build process"))
+					Expect(buffer.String()).NotTo(ContainSubstring("Ignoring RUNTIME_VERSION"))
+				})
+			})
+
+			context("when the user set another version", func() {
+				it.Before(func() {
+					Expect(os.Setenv("RUNTIME_VERSION", "other-version")).To(Succeed())
+				})
+
+				it("warns that the variable is deprecated", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(buffer.String()).To(ContainSubstring("WARNING: Setting the .NET Framework version through RUNTIME_VERSION is deprecated"))
+				})
+			})
+		})
+
+		context("failure cases", func() {
+			context("when BP_DOTNET_IGNORE_RUNTIME_VERSION is not a boolean", func() {
+				it.Before(func() {
+					Expect(os.Setenv("BP_DOTNET_IGNORE_RUNTIME_VERSION", "sometimes")).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Unsetenv("BP_DOTNET_IGNORE_RUNTIME_VERSION")).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Layers:     packit.Layers{Path: layersDir},
+					})
+					Expect(err).To(MatchError(`invalid value for BP_DOTNET_IGNORE_RUNTIME_VERSION "sometimes": must be a boolean`))
+				})
+			})
 		})
 	})
 
-	context("when version-source of the selected entry is buildpack.yml", func() {
+	context("when the version-source of the selected entry is runtimeconfig.json", func() {
 		it.Before(func() {
 			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
 				Name: "dotnet-aspnetcore",
 				Metadata: map[string]interface{}{
-					"version-source": "buildpack.yml",
-					"version":        "2.5.x",
+					"version-source": "runtimeconfig.json",
+					"version":        "6.0.0",
 				},
 			}
 		})
-		it("chooses the specified version and emits a warning", func() {
+
+		it("resolves a version using the default roll-forward policy", func() {
 			_, err := build(packit.BuildContext{
 				WorkingDir: workingDir,
 				CNBPath:    cnbDir,
 				Stack:      "some-stack",
 				BuildpackInfo: packit.BuildpackInfo{
 					Name:    "Some Buildpack",
-					Version: "0.1.2",
-				},
-				Plan: packit.BuildpackPlan{
-					Entries: []packit.BuildpackPlanEntry{
-						{
-							Name: "dotnet-aspnetcore",
-							Metadata: map[string]interface{}{
-								"version-source": "buildpack.yml",
-								"version":        "2.5.x",
-							},
-						},
-					},
+					Version: "some-version",
 				},
 				Layers: packit.Layers{Path: layersDir},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(buffer.String()).To(ContainSubstring("Some Buildpack 0.1.2"))
-			Expect(buffer.String()).To(ContainSubstring("Resolving .NET Core ASPNet version"))
-			Expect(buffer.String()).To(ContainSubstring("Selected .NET Core ASPNet version (using buildpack.yml): "))
-			// v1.0.0 because that's the next major after input version v0.1.2
-			Expect(buffer.String()).To(ContainSubstring("WARNING: Setting the .NET Framework version through buildpack.yml will be deprecated soon in .NET Core ASPNet Buildpack v1.0.0."))
-			Expect(buffer.String()).To(ContainSubstring("Please specify the version through the $BP_DOTNET_FRAMEWORK_VERSION environment variable instead. See docs for more information."))
-			Expect(buffer.String()).To(ContainSubstring("Executing build process"))
-			Expect(buffer.String()).To(ContainSubstring("Configuring launch environment"))
+			Expect(dependencyManager.ResolveCall.CallCount).To(Equal(1))
+			Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal(">= 6.0.0, < 6.1.0"))
+
+			Expect(buffer.String()).To(ContainSubstring("Applying roll-forward policy Minor (from default)"))
 		})
-	})
 
-	context("failure cases", func() {
-		context("when the dependency cannot be resolved", func() {
+		context("when no version of the requested minor is available", func() {
 			it.Before(func() {
-				dependencyManager.ResolveCall.Returns.Error = errors.New("failed to resolve dependency")
+				catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.2.0"}},
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.2.3"}},
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.4.1"}},
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.0"}},
+				}
+
+				dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+					if version != ">= 6.2.0, < 6.3.0" {
+						return postal.Dependency{}, errors.New("no compatible versions")
+					}
+
+					return postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.2.3"}, nil
+				}
 			})
 
-			it("returns an error", func() {
+			it("rolls forward to the latest patch of the lowest higher minor version", func() {
 				_, err := build(packit.BuildContext{
-					CNBPath: cnbDir,
-					Plan: packit.BuildpackPlan{
-						Entries: []packit.BuildpackPlanEntry{
-							{Name: "dotnet-aspnetcore"},
-						},
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
 					},
 					Layers: packit.Layers{Path: layersDir},
-					Stack:  "some-stack",
 				})
-				Expect(err).To(MatchError("failed to resolve dependency"))
-			})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.CallCount).To(Equal(2))
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal(">= 6.2.0, < 6.3.0"))
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.2.3"))
+			})
+
+			context("when the policy is Major and no version of the requested major is available", func() {
+				it.Before(func() {
+					catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+						{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "8.0.2"}},
+						{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "8.1.0"}},
+						{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "9.0.0"}},
+					}
+
+					dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+						if version != ">= 8.0.0, < 8.1.0" {
+							return postal.Dependency{}, errors.New("no compatible versions")
+						}
+
+						return postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "8.0.2"}, nil
+					}
+
+					entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["roll-forward"] = "Major"
+				})
+
+				it("rolls forward to the latest patch of the lowest minor of the lowest higher major version", func() {
+					_, err := build(packit.BuildContext{
+						WorkingDir: workingDir,
+						CNBPath:    cnbDir,
+						Stack:      "some-stack",
+						BuildpackInfo: packit.BuildpackInfo{
+							Name:    "Some Buildpack",
+							Version: "some-version",
+						},
+						Layers: packit.Layers{Path: layersDir},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(dependencyManager.ResolveCall.CallCount).To(Equal(2))
+					Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal(">= 8.0.0, < 8.1.0"))
+					Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("8.0.2"))
+				})
+			})
+		})
+
+		context("when the runtimeconfig.json declares a roll-forward policy", func() {
+			it.Before(func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["roll-forward"] = "latestMajor"
+			})
+
+			it("resolves a version using that policy", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal(">= 6.0.0"))
+
+				Expect(buffer.String()).To(ContainSubstring("Applying roll-forward policy LatestMajor (from runtimeconfig.json)"))
+			})
+		})
+
+		context("when the BP_DOTNET_ROLL_FORWARD env variable is set", func() {
+			it.Before(func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["roll-forward"] = "LatestMajor"
+				Expect(os.Setenv("BP_DOTNET_ROLL_FORWARD", "Disable")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_ROLL_FORWARD")).To(Succeed())
+			})
+
+			it("overrides the policy in the runtimeconfig.json", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("6.0.0"))
+
+				Expect(buffer.String()).To(ContainSubstring("Applying roll-forward policy Disable (from BP_DOTNET_ROLL_FORWARD)"))
+			})
+		})
+	})
+
+	context("when the version sources disagree on the major.minor version", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+					"version":        "6.0.*",
+				},
+			}
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "6.0.*",
+							},
+						},
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "some-app.csproj",
+								"version":        "6.0.*",
+							},
+						},
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "runtimeconfig.json",
+								"version":        "7.0.0",
+							},
+						},
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version": "8.0.0",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		it("warns about the sources that disagree", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("WARNING: The version requests for .NET Core ASPNet disagree on the major.minor version:"))
+			Expect(buffer.String()).To(ContainSubstring("BP_DOTNET_FRAMEWORK_VERSION requests 6.0.* (selected)"))
+			Expect(buffer.String()).To(ContainSubstring("runtimeconfig.json requests 7.0.0"))
+			Expect(buffer.String()).NotTo(ContainSubstring("some-app.csproj requests"))
+			Expect(buffer.String()).NotTo(ContainSubstring("requests 8.0.0"))
+			Expect(buffer.String()).To(ContainSubstring("Set BP_DOTNET_STRICT_VERSION=true to fail the build on conflicting version requests."))
+		})
+
+		context("when the selected source does not pin a major.minor version", func() {
+			it.Before(func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "*"
+				buildContext.Plan.Entries[0].Metadata["version"] = "*"
+			})
+
+			it("does not warn", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).NotTo(ContainSubstring("disagree"))
+			})
+		})
+
+		context("when BP_DOTNET_STRICT_VERSION is true", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_STRICT_VERSION", "true")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_STRICT_VERSION")).To(Succeed())
+			})
+
+			it("fails the build", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to satisfy version requests: BP_DOTNET_FRAMEWORK_VERSION requests 6.0.*, but runtimeconfig.json requests 7.0.0"))
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+			})
+		})
+	})
+
+	context("when the DOTNET_ROLL_FORWARD env variable is set", func() {
+		it.Before(func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+					"version":        "6.0.12",
+				},
+			}
+			Expect(os.Setenv("DOTNET_ROLL_FORWARD", "LatestPatch")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("DOTNET_ROLL_FORWARD")).To(Succeed())
+		})
+
+		it("applies the policy to versions from any source", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal(">= 6.0.12, < 6.1.0"))
+
+			Expect(buffer.String()).To(ContainSubstring("Applying roll-forward policy LatestPatch (from DOTNET_ROLL_FORWARD)"))
+		})
+	})
+
+	context("when the DOTNET_ROOT env variable is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("DOTNET_ROOT", "some-dotnet-root")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("DOTNET_ROOT")).To(Succeed())
+		})
+
+		it("validates the runtime in that location as well", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(validator.ValidateCall.Receives.DotnetRoots).To(Equal([]string{
+				filepath.Join(workingDir, ".dotnet_root"),
+				"some-dotnet-root",
+			}))
+		})
+	})
+
+	context("when BP_DOTNET_ROOT_STRATEGY is set to layer", func() {
+		var dotnetRoot string
+
+		it.Before(func() {
+			Expect(os.Setenv("BP_DOTNET_ROOT_STRATEGY", "layer")).To(Succeed())
+
+			var err error
+			dotnetRoot, err = os.MkdirTemp("", "dotnet-root")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Setenv("DOTNET_ROOT", dotnetRoot)).To(Succeed())
+
+			entryResolver.MergeLayerTypesCall.Returns.Build = true
+
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_DOTNET_ROOT_STRATEGY")).To(Succeed())
+			Expect(os.Unsetenv("DOTNET_ROOT")).To(Succeed())
+			Expect(os.RemoveAll(dotnetRoot)).To(Succeed())
+		})
+
+		it("hosts the dotnet root in a dedicated layer", func() {
+			var links [][]string
+			symlinker.LinkCall.Stub = func(root, layerPath string) error {
+				links = append(links, []string{root, layerPath})
+				return nil
+			}
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "2.5.x",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers[0].Name).To(Equal("dotnet-core-aspnet"))
+			Expect(result.Layers[0].LaunchEnv).To(BeEmpty())
+
+			rootLayer := result.Layers[1]
+			Expect(rootLayer.Name).To(Equal("dotnet-root"))
+			Expect(rootLayer.Path).To(Equal(filepath.Join(layersDir, "dotnet-root")))
+			Expect(rootLayer.SharedEnv).To(Equal(packit.Environment{
+				"DOTNET_ROOT.override": filepath.Join(layersDir, "dotnet-root"),
+			}))
+			Expect(rootLayer.Cache).To(BeFalse())
+
+			Expect(links).To(Equal([][]string{
+				{filepath.Join(layersDir, "dotnet-root"), dotnetRoot},
+				{filepath.Join(layersDir, "dotnet-root"), filepath.Join(layersDir, "dotnet-core-aspnet")},
+			}))
+
+			Expect(filepath.Join(workingDir, ".dotnet_root")).NotTo(BeADirectory())
+		})
+	})
+
+	context("when multiple versions are requested through the selected version source", func() {
+		it.Before(func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+					"version":        "6.0.*",
+				},
+			}
+
+			dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+				switch version {
+				case "6.0.*":
+					return postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", SHA256: "some-6-sha"}, nil
+				default:
+					return postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.2", SHA256: "some-7-sha"}, nil
+				}
+			}
+		})
+
+		it("installs each version into the layer", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:        "Some Buildpack",
+					Version:     "some-version",
+					SBOMFormats: []string{sbom.CycloneDXFormat},
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+								"version":        "6.0.*",
+							},
+						},
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+								"version":        "7.0.*",
+							},
+						},
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "runtimeconfig.json",
+								"version":        "5.0.0",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			layer := result.Layers[0]
+
+			Expect(layer.Metadata).To(Equal(map[string]interface{}{
+				"dependency-shas": map[string]interface{}{
+					"6.0.13": "some-6-sha",
+					"7.0.2":  "some-7-sha",
+				},
+				"content-manifest": dotnetcoreaspnet.ContentManifest{},
+				"cache-key":        cacheKey,
+			}))
+
+			Expect(dependencyManager.ResolveCall.CallCount).To(Equal(2))
+			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(2))
+			Expect(dependencyManager.GenerateBillOfMaterialsCall.Receives.Dependencies).To(Equal([]postal.Dependency{
+				{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", SHA256: "some-6-sha"},
+				{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.2", SHA256: "some-7-sha"},
+			}))
+
+			Expect(symlinker.LinkCall.CallCount).To(Equal(1))
+
+			Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dependencies).To(Equal([]postal.Dependency{
+				{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", SHA256: "some-6-sha"},
+				{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.2", SHA256: "some-7-sha"},
+			}))
+			Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dir).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+
+			Expect(buffer.String()).To(ContainSubstring("Installing .NET Core ASPNet 6.0.13"))
+			Expect(buffer.String()).To(ContainSubstring("Installing .NET Core ASPNet 7.0.2"))
+		})
+
+		context("when the versions resolve to the same dependency", func() {
+			it.Before(func() {
+				dependencyManager.ResolveCall.Stub = nil
+			})
+
+			it("installs the dependency once", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{
+								Name: "dotnet-aspnetcore",
+								Metadata: map[string]interface{}{
+									"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+									"version":        "2.5.*",
+								},
+							},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.CallCount).To(Equal(2))
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dependencies).To(HaveLen(1))
+			})
+		})
+	})
+
+	context("when multiple versions are requested through BP_DOTNET_ASPNETCORE_VERSIONS", func() {
+		var detect packit.DetectFunc
+
+		it.Before(func() {
+			Expect(os.Setenv("BP_DOTNET_ASPNETCORE_VERSIONS", "6.0.*,7.0.*")).To(Succeed())
+
+			detect = dotnetcoreaspnet.Detect(&fakes.VersionParser{}, &fakes.ConfigParser{}, &fakes.ProjectParser{})
+
+			dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+				switch version {
+				case "6.0.*":
+					return postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", SHA256: "some-6-sha"}, nil
+				case "7.0.*":
+					return postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.2", SHA256: "some-7-sha"}, nil
+				default:
+					return postal.Dependency{}, fmt.Errorf("no version matches %q", version)
+				}
+			}
+
+			build = dotnetcoreaspnet.Build(draft.NewPlanner(), dependencyManager, downloadCache, catalog, analyzer, symlinker, validator, verifier, sbomGenerator, scribe.NewEmitter(buffer), chronos.DefaultClock)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_DOTNET_ASPNETCORE_VERSIONS")).To(Succeed())
+		})
+
+		it("requires the runtime of each release and installs each version", func() {
+			detected, err := detect(packit.DetectContext{WorkingDir: workingDir})
+			Expect(err).NotTo(HaveOccurred())
+
+			// the runtime buildpack resolves its own requirements, each of
+			// which must be satisfiable by a single version
+			var runtimeVersions []interface{}
+			var entries []packit.BuildpackPlanEntry
+			for _, requirement := range detected.Plan.Requires {
+				metadata := requirement.Metadata.(map[string]interface{})
+				if requirement.Name == "dotnet-runtime" {
+					runtimeVersions = append(runtimeVersions, metadata["version"])
+					continue
+				}
+
+				entries = append(entries, packit.BuildpackPlanEntry{Name: requirement.Name, Metadata: metadata})
+			}
+			Expect(runtimeVersions).To(Equal([]interface{}{"6.0.*", "7.0.*"}))
+			_, ok := os.LookupEnv("BP_DOTNET_FRAMEWORK_VERSION")
+			Expect(ok).To(BeFalse())
+
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan:   packit.BuildpackPlan{Entries: entries},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(2))
+			Expect(result.Layers[0].SharedEnv).To(HaveKeyWithValue("DOTNET_ASPNETCORE_VERSION.override", "6.0.13,7.0.2"))
+			Expect(buffer.String()).To(ContainSubstring("Installing .NET Core ASPNet 6.0.13"))
+			Expect(buffer.String()).To(ContainSubstring("Installing .NET Core ASPNet 7.0.2"))
+		})
+
+		context("when the runtime of only one release is installed", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared", "Microsoft.NETCore.App", "6.0.13"), os.ModePerm)).To(Succeed())
+
+				dependencyManager.DeliverCall.Stub = func(dependency postal.Dependency, _, layerPath, _ string) error {
+					return os.MkdirAll(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", dependency.Version), os.ModePerm)
+				}
+
+				validator := dotnetcoreaspnet.NewRuntimeCompatibilityValidator(scribe.NewEmitter(buffer))
+				build = dotnetcoreaspnet.Build(draft.NewPlanner(), dependencyManager, downloadCache, catalog, analyzer, symlinker, validator, verifier, sbomGenerator, scribe.NewEmitter(buffer), chronos.DefaultClock)
+			})
+
+			it("installs each version and warns about the release without a runtime", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore", Metadata: map[string]interface{}{"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS", "version": "6.0.*"}},
+							{Name: "dotnet-aspnetcore", Metadata: map[string]interface{}{"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS", "version": "7.0.*"}},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(2))
+				Expect(buffer.String()).To(ContainSubstring("WARNING: ASP.NET Core 7.0.2 requires Microsoft.NETCore.App 7.0.0 or a later 7.0 patch, but only [6.0.13] is installed"))
+			})
+		})
+	})
+
+	context("when the framework is only required at launch", func() {
+		it.Before(func() {
+			entryResolver.MergeLayerTypesCall.Returns.Launch = true
+			entryResolver.MergeLayerTypesCall.Returns.Build = false
+		})
+
+		it("still writes the installed frameworks to the aspnetcore.toml file", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "2.5.x",
+								"launch":         true,
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.Build).To(BeFalse())
+			Expect(layer.BuildEnv).To(BeEmpty())
+			Expect(layer.SharedEnv).To(HaveKeyWithValue("DOTNET_ASPNETCORE_VERSION.override", "2.5.1"))
+
+			content, err := os.ReadFile(filepath.Join(layersDir, "dotnet-core-aspnet", "aspnetcore.toml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`version = "2.5.1"`))
+		})
+	})
+
+	context("when the build plan entry include build, launch flags", func() {
+		it.Before(func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+					"version":        "2.5.x",
+					"build":          true,
+					"launch":         true,
+				},
+			}
+
+			entryResolver.MergeLayerTypesCall.Returns.Launch = true
+			entryResolver.MergeLayerTypesCall.Returns.Build = true
+		})
+
+		it("marks the layer as build, cache and launch", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "2.5.x",
+								"build":          true,
+								"launch":         true,
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			layer := result.Layers[0]
+
+			Expect(layer.Name).To(Equal("dotnet-core-aspnet"))
+			Expect(layer.Path).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+			Expect(layer.SharedEnv).To(Equal(packit.Environment{
+				"DOTNET_ROOT.override":               filepath.Join(workingDir, ".dotnet_root"),
+				"DOTNET_ASPNETCORE_VERSION.override": "2.5.1",
+				"DOTNET_ASPNETCORE_PATH.override":    filepath.Join(layersDir, "dotnet-core-aspnet", "shared", "Microsoft.AspNetCore.App"),
+			}))
+			Expect(layer.Metadata).To(Equal(map[string]interface{}{
+				"dependency-shas":  map[string]interface{}{"2.5.1": ""},
+				"content-manifest": dotnetcoreaspnet.ContentManifest{},
+				"cache-key":        cacheKey,
+			}))
+
+			Expect(layer.Build).To(BeTrue())
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.Cache).To(BeTrue())
+
+			Expect(result.Build.BOM).To(HaveLen(1))
+			buildBOMEntry := result.Build.BOM[0]
+			Expect(buildBOMEntry.Name).To(Equal("dotnet-aspnetcore"))
+			Expect(buildBOMEntry.Metadata).To(Equal(paketosbom.BOMMetadata{
+				Version: "dotnet-aspnetcore-dep-version",
+				Checksum: paketosbom.BOMChecksum{
+					Algorithm: paketosbom.SHA256,
+					Hash:      "dotnet-aspnetcore-dep-sha",
+				},
+				URI: "dotnet-aspnetcore-dep-uri",
+			}))
+
+			Expect(result.Launch.BOM).To(HaveLen(1))
+			launchBOMEntry := result.Launch.BOM[0]
+			Expect(launchBOMEntry.Name).To(Equal("dotnet-aspnetcore"))
+			Expect(launchBOMEntry.Metadata).To(Equal(paketosbom.BOMMetadata{
+				Version: "dotnet-aspnetcore-dep-version",
+				Checksum: paketosbom.BOMChecksum{
+					Algorithm: paketosbom.SHA256,
+					Hash:      "dotnet-aspnetcore-dep-sha",
+				},
+				URI: "dotnet-aspnetcore-dep-uri",
+			}))
+		})
+	})
+
+	context("when the build plan entry only includes the build flag", func() {
+		it.Before(func() {
+			entryResolver.MergeLayerTypesCall.Returns.Launch = false
+			entryResolver.MergeLayerTypesCall.Returns.Build = true
+		})
+
+		it("sets DOTNET_ROOT in the build environment", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "2.5.x",
+								"build":          true,
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			layer := result.Layers[0]
+
+			Expect(layer.BuildEnv).To(Equal(packit.Environment{
+				"DOTNET_ROOT.override": filepath.Join(workingDir, ".dotnet_root"),
+			}))
+			Expect(layer.LaunchEnv).To(BeEmpty())
+			Expect(layer.SharedEnv).To(Equal(packit.Environment{
+				"DOTNET_ASPNETCORE_VERSION.override": "2.5.1",
+				"DOTNET_ASPNETCORE_PATH.override":    filepath.Join(layersDir, "dotnet-core-aspnet", "shared", "Microsoft.AspNetCore.App"),
+			}))
+
+			Expect(layer.Build).To(BeTrue())
+			Expect(layer.Launch).To(BeFalse())
+		})
+	})
+
+	context("when newer patches of the selected version are available", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+				return postal.Dependency{
+					ID:      "dotnet-aspnetcore",
+					Name:    ".NET Core ASPNet",
+					Version: version,
+				}, nil
+			}
+
+			catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.0.12"}},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.0.13"}, Security: true},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.0.14"}},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.1"}},
+			}
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "6.0.12",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = buildContext.Plan.Entries[0]
+		})
+
+		it("reports the newer patches", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(catalog.DependenciesCall.Receives.Path).To(Equal(filepath.Join(cnbDir, "buildpack.toml")))
+			Expect(catalog.DependenciesCall.Receives.Id).To(Equal("dotnet-aspnetcore"))
+
+			Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.0.12"))
+
+			Expect(buffer.String()).To(ContainSubstring("Newer patches of .NET Core ASPNet 6.0.12 are available: 6.0.13 (security), 6.0.14"))
+			Expect(buffer.String()).To(ContainSubstring("Set BP_DOTNET_AUTO_UPGRADE_PATCHES=true to upgrade pinned versions to the latest patch."))
+		})
+
+		context("when BP_DOTNET_AUTO_UPGRADE_PATCHES is true", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_AUTO_UPGRADE_PATCHES", "true")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_AUTO_UPGRADE_PATCHES")).To(Succeed())
+			})
+
+			it("upgrades the pinned version to the latest patch", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("6.0.14"))
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.0.14"))
+
+				Expect(buffer.String()).To(ContainSubstring("Upgrading pinned version 6.0.12 to the latest patch 6.0.14"))
+				Expect(buffer.String()).NotTo(ContainSubstring("Newer patches"))
+			})
+
+			context("when the version is pinned through RUNTIME_VERSION", func() {
+				it.Before(func() {
+					buildContext.Plan.Entries[0].Metadata["version-source"] = "RUNTIME_VERSION"
+					entryResolver.ResolveCall.Returns.BuildpackPlanEntry = buildContext.Plan.Entries[0]
+				})
+
+				it("keeps the version of the installed runtime", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(dependencyManager.ResolveCall.CallCount).To(Equal(1))
+					Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.0.12"))
+
+					Expect(buffer.String()).To(ContainSubstring("Newer patches of .NET Core ASPNet 6.0.12 are available: 6.0.13 (security), 6.0.14"))
+					Expect(buffer.String()).To(ContainSubstring("Keeping 6.0.12, which matches the installed .NET Core Runtime version in RUNTIME_VERSION."))
+					Expect(buffer.String()).NotTo(ContainSubstring("Upgrading pinned version"))
+				})
+			})
+		})
+	})
+
+	context("when the requested version is an alias", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+				return postal.Dependency{
+					ID:      "dotnet-aspnetcore",
+					Name:    ".NET Core ASPNet",
+					Version: version,
+				}, nil
+			}
+
+			catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "6.0.13"}, Channel: "lts"},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.1"}, Channel: "sts"},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.2"}, Channel: "sts"},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "8.0.0"}},
+			}
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		for alias, version := range map[string]string{
+			"lts":    "8.0.0",
+			"STS":    "7.0.2",
+			"latest": "8.0.0",
+			"6":      "6.0.13",
+		} {
+			alias, version := alias, version
+
+			it(fmt.Sprintf("resolves %s to %s", alias, version), func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+						"version":        alias,
+					},
+				}
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal(version))
+				Expect(buffer.String()).To(ContainSubstring("Selected .NET Core ASPNet version (using BP_DOTNET_FRAMEWORK_VERSION, alias %s): %s", alias, version))
+			})
+		}
+
+		context("when no version matches the alias", func() {
+			it("returns an error", func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+						"version":        "5",
+					},
+				}
+
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`failed to resolve version alias "5": no version of dotnet-aspnetcore matches`))
+			})
+		})
+	})
+
+	context("when the catalog includes a prerelease version", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+				switch version {
+				case "8.0.0-rc.2", "7.0.2":
+					return postal.Dependency{ID: id, Name: ".NET Core ASPNet", Version: version}, nil
+				case "8.0.*":
+					return postal.Dependency{}, errors.New("failed to satisfy \"8.0.*\" dependency version constraint")
+				}
+
+				return postal.Dependency{ID: id, Name: ".NET Core ASPNet", Version: "7.0.2"}, nil
+			}
+
+			catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.2"}},
+				{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "8.0.0-rc.2"}},
+			}
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		it("does not select it", func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "latest"
+
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("7.0.2"))
+		})
+
+		it("refuses it when it is pinned", func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "8.0.0-rc.2"
+
+			_, err := build(buildContext)
+			Expect(err).To(MatchError("failed to resolve dotnet-aspnetcore 8.0.0-rc.2: 8.0.0-rc.2 is a prerelease version, set BP_DOTNET_ALLOW_PRERELEASE=true to allow it"))
+		})
+
+		context("when BP_DOTNET_ALLOW_PRERELEASE is true", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_ALLOW_PRERELEASE", "true")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_ALLOW_PRERELEASE")).To(Succeed())
+			})
+
+			it("selects the prerelease matching the constraint and marks it", func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "8.0.*"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("8.0.0-rc.2"))
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("8.0.0-rc.2"))
+				Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dependencies).To(Equal([]postal.Dependency{
+					dependencyManager.DeliverCall.Receives.Dependency,
+				}))
+				Expect(sbomGenerator.GenerateFromDependenciesCall.Receives.Dependencies[0].Name).To(Equal(".NET Core ASPNet"))
+
+				Expect(buffer.String()).To(ContainSubstring("WARNING: .NET Core ASPNet 8.0.0-rc.2 is a prerelease version and is not supported for production use."))
+			})
+
+			it("selects the prerelease for the latest alias", func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "latest"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("8.0.0-rc.2"))
+			})
+
+			it("prefers a later release over the prerelease", func() {
+				catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.0-rc.2"}},
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Version: "7.0.2"}},
+				}
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "7.0.*"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("7.0.2"))
+			})
+		})
+	})
+
+	context("when the dependencies are built for multiple architectures", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			Expect(os.Setenv("CNB_TARGET_ARCH", "arm64")).To(Succeed())
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "6.0.13",
+				URI:     "some-x64-uri",
+				SHA256:  "some-x64-sha",
+			}
+
+			catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+				{
+					Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", URI: "some-x64-uri", SHA256: "some-x64-sha"},
+					Arch:       "amd64",
+				},
+				{
+					Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", URI: "some-arm64-uri", SHA256: "some-arm64-sha"},
+					Arch:       "arm64",
+				},
+			}
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("CNB_TARGET_ARCH")).To(Succeed())
+		})
+
+		it("installs the artifact for the target architecture", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.DeliverCall.Receives.Dependency).To(Equal(postal.Dependency{
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "6.0.13",
+				URI:     "some-arm64-uri",
+				SHA256:  "some-arm64-sha",
+			}))
+		})
+
+		context("when the selected version has no artifact for the target architecture", func() {
+			it.Before(func() {
+				catalog.DependenciesCall.Returns.CatalogDependencySlice[1].Version = "6.0.12"
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "6.0.*"
+
+				dependencyManager.ResolveCall.Stub = func(path, id, version, stack string) (postal.Dependency, error) {
+					if version == "6.0.12" {
+						return catalog.DependenciesCall.Returns.CatalogDependencySlice[1].Dependency, nil
+					}
+
+					return catalog.DependenciesCall.Returns.CatalogDependencySlice[0].Dependency, nil
+				}
+			})
+
+			it("falls back to the latest requested version that has one", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.ResolveCall.Receives.Version).To(Equal("6.0.12"))
+				Expect(dependencyManager.DeliverCall.Receives.Dependency).To(Equal(postal.Dependency{
+					ID:      "dotnet-aspnetcore",
+					Name:    ".NET Core ASPNet",
+					Version: "6.0.12",
+					URI:     "some-arm64-uri",
+					SHA256:  "some-arm64-sha",
+				}))
+			})
+
+			context("when no requested version has an artifact for the target architecture", func() {
+				it.Before(func() {
+					catalog.DependenciesCall.Returns.CatalogDependencySlice[1].Version = "7.0.1"
+				})
+
+				it("returns an error listing the versions that are available", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError("no dotnet-aspnetcore 6.0.13 artifact is available for the arm64 architecture: request one of the versions available for arm64: 7.0.1"))
+				})
+			})
+		})
+	})
+
+	context("when the platform provides the distribution of the target", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			Expect(os.Setenv("CNB_TARGET_OS", "linux")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_ARCH", "amd64")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_DISTRO_NAME", "ubuntu")).To(Succeed())
+			Expect(os.Setenv("CNB_TARGET_DISTRO_VERSION", "22.04")).To(Succeed())
+
+			catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+				{
+					Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", URI: "some-jammy-uri"},
+					Distros:    []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "22.04"}},
+				},
+				{
+					Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.14", URI: "some-bionic-uri"},
+					Distros:    []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "18.04"}},
+				},
+			}
+
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "6.0.*"
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("CNB_TARGET_OS")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_ARCH")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_DISTRO_NAME")).To(Succeed())
+			Expect(os.Unsetenv("CNB_TARGET_DISTRO_VERSION")).To(Succeed())
+		})
+
+		it("resolves the dependency built for the distribution", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dependencyManager.ResolveCall.CallCount).To(Equal(0))
+			Expect(dependencyManager.DeliverCall.Receives.Dependency).To(Equal(postal.Dependency{
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "6.0.13",
+				URI:     "some-jammy-uri",
+			}))
+		})
+
+		context("when the dependencies only list the stacks they are built for", func() {
+			it.Before(func() {
+				catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", URI: "some-jammy-uri", Stacks: []string{"io.buildpacks.stacks.jammy"}}},
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.14", URI: "some-bionic-uri", Stacks: []string{"io.buildpacks.stacks.bionic"}}},
+				}
+			})
+
+			it("resolves the dependency built for the stack of the distribution", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.URI).To(Equal("some-jammy-uri"))
+			})
+		})
+
+		context("when the version uses the pessimistic operator", func() {
+			it.Before(func() {
+				catalog.DependenciesCall.Returns.CatalogDependencySlice = append(catalog.DependenciesCall.Returns.CatalogDependencySlice,
+					dotnetcoreaspnet.CatalogDependency{
+						Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.1.0", URI: "some-minor-uri"},
+						Distros:    []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "22.04"}},
+					},
+					dotnetcoreaspnet.CatalogDependency{
+						Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.0", URI: "some-major-uri"},
+						Distros:    []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "22.04"}},
+					},
+				)
+			})
+
+			it("allows later minor versions when it names a minor version", func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "~> 6.0"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.1.0"))
+			})
+
+			it("allows later patches when it names a patch version", func() {
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "~> 6.0.1"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.0.13"))
+			})
+		})
+
+		context("when no version is requested", func() {
+			it.Before(func() {
+				catalog.DependenciesCall.Returns.CatalogDependencySlice = append(catalog.DependenciesCall.Returns.CatalogDependencySlice,
+					dotnetcoreaspnet.CatalogDependency{
+						Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.0", URI: "some-major-uri"},
+						Distros:    []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "22.04"}},
+					},
+				)
+				catalog.DefaultVersionCall.Returns.String = "6.0.*"
+
+				entryResolver.ResolveCall.Returns.BuildpackPlanEntry.Metadata["version"] = "default"
+			})
+
+			it("resolves the default version of the buildpack.toml", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(catalog.DefaultVersionCall.Receives.Path).To(Equal(filepath.Join(cnbDir, "buildpack.toml")))
+				Expect(catalog.DefaultVersionCall.Receives.Id).To(Equal("dotnet-aspnetcore"))
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("6.0.13"))
+			})
+
+			context("when the buildpack.toml declares no default version", func() {
+				it.Before(func() {
+					catalog.DefaultVersionCall.Returns.String = ""
+				})
+
+				it("resolves the latest version", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(dependencyManager.DeliverCall.Receives.Dependency.Version).To(Equal("7.0.0"))
+				})
+			})
+		})
+
+		context("when a version is built for the stack and for any stack", func() {
+			it.Before(func() {
+				catalog.DependenciesCall.Returns.CatalogDependencySlice = []dotnetcoreaspnet.CatalogDependency{
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", URI: "some-jammy-uri", Stacks: []string{"io.buildpacks.stacks.jammy"}}},
+					{Dependency: postal.Dependency{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", URI: "some-any-uri", Stacks: []string{"*"}}},
+				}
+			})
+
+			it("resolves the dependency built for the stack", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.Receives.Dependency.URI).To(Equal("some-jammy-uri"))
+			})
+		})
+
+		context("when the buildpack.toml can not be read for the default version", func() {
+			it.Before(func() {
+				catalog.DefaultVersionCall.Returns.Error = errors.New("failed to read default version")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to read default version"))
+			})
+		})
+
+		context("when no dependency is built for the distribution", func() {
+			it.Before(func() {
+				Expect(os.Setenv("CNB_TARGET_DISTRO_VERSION", "24.04")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`failed to satisfy "6.0.*" dependency version constraint for dotnet-aspnetcore on ubuntu 24.04 (linux/amd64): no compatible versions. Supported versions are: []`))
+			})
+		})
+	})
+
+	context("when the selected dependency reaches its end of support", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			dependencyManager.ResolveCall.Returns.Dependency.DeprecationDate = time.Now().AddDate(0, 0, 30)
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "2.5.x",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		it("warns that the end of support is approaching", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("WARNING: .NET Core ASPNet 2.5.1 reaches its end of support on %s.", time.Now().AddDate(0, 0, 30).Format("2006-01-02")))
+		})
+
+		context("when the end of support is outside of the configured window", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_EOL_WARNING_DAYS", "7")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_EOL_WARNING_DAYS")).To(Succeed())
+			})
+
+			it("does not warn", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).NotTo(ContainSubstring("end of support"))
+			})
+		})
+
+		context("when the dependency is already out of support", func() {
+			it.Before(func() {
+				dependencyManager.ResolveCall.Returns.Dependency.DeprecationDate = time.Now().AddDate(0, 0, -1)
+			})
+
+			it("only reports the deprecation once", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(ContainSubstring("Version 2.5.1 of .NET Core ASPNet is deprecated."))
+				Expect(strings.Count(buffer.String(), "Migrate your application to a supported version of .NET Core ASPNet")).To(Equal(1))
+				Expect(buffer.String()).NotTo(ContainSubstring("WARNING: .NET Core ASPNet 2.5.1"))
+			})
+
+			context("when BP_DOTNET_FAIL_ON_EOL is true", func() {
+				it.Before(func() {
+					Expect(os.Setenv("BP_DOTNET_FAIL_ON_EOL", "true")).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Unsetenv("BP_DOTNET_FAIL_ON_EOL")).To(Succeed())
+				})
+
+				it("fails the build", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError("failed to satisfy support policy: .NET Core ASPNet 2.5.1 is out of support and BP_DOTNET_FAIL_ON_EOL is set"))
+
+					Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+	})
+
+	context("when the application shows no sign of needing ASP.NET Core", func() {
+		var buildContext packit.BuildContext
+
+		it.Before(func() {
+			analyzer.AnalyzeCall.Returns.AppAnalysis = dotnetcoreaspnet.AppAnalysis{
+				Reason: "no reference to ASP.NET Core was found in a *.runtimeconfig.json, *.deps.json or project file",
+			}
+
+			buildContext = packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								"version":        "2.5.x",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			}
+		})
+
+		it("installs the framework and warns that it may be unnecessary", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(analyzer.AnalyzeCall.Receives.WorkingDir).To(Equal(workingDir))
+			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+
+			Expect(buffer.String()).To(ContainSubstring("WARNING: Installing .NET Core ASPNet for an application that shows no sign of needing it: no reference to ASP.NET Core was found in a *.runtimeconfig.json, *.deps.json or project file."))
+			Expect(buffer.String()).To(ContainSubstring("Another buildpack may be requiring dotnet-aspnetcore unnecessarily."))
+		})
+
+		context("failure cases", func() {
+			context("when the application cannot be analyzed", func() {
+				it.Before(func() {
+					analyzer.AnalyzeCall.Returns.Error = errors.New("failed to analyze")
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError("failed to analyze"))
+				})
+			})
+		})
+	})
+
+	context("when there is a dependency cache match", func() {
+		it.Before(func() {
+			err := os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet.toml"), []byte(fmt.Sprintf(`[metadata.dependency-shas]
+"2.5.1" = "some-sha"
+
+[metadata.content-manifest.some-file]
+size = 12
+sha256 = "0a8cac771ca188eacc57e2c96c31f5611925c5ecedccb16b8c236d6c0d325112"
+
+[metadata.cache-key]
+buildpack-version = %q
+target = %q
+dotnet-root-strategy = "working-dir"
+dotnet-root-link-mode = "framework"
+`, "some-version", cacheKey["target"])), 0600)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(layersDir, "dotnet-core-aspnet"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet", "some-file"), []byte("some-content"), 0600)).To(Succeed())
+
+			dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+				ID:      "dotnet-aspnetcore",
+				Name:    ".NET Core ASPNet",
+				Version: "2.5.1",
+				SHA256:  "some-sha",
+			}
+			entryResolver.MergeLayerTypesCall.Returns.Launch = true
+			entryResolver.MergeLayerTypesCall.Returns.Build = false
+		})
+
+		it("exits build process early", func() {
+			result, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "some-version",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version":        "2.5.x",
+								"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(2))
+			layer := result.Layers[0]
+
+			Expect(layer.Name).To(Equal("dotnet-core-aspnet"))
+			Expect(layer.Path).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+			Expect(layer.Metadata).To(Equal(map[string]interface{}{
+				"dependency-shas": map[string]interface{}{"2.5.1": "some-sha"},
+				"content-manifest": map[string]interface{}{
+					"some-file": map[string]interface{}{
+						"size":   int64(12),
+						"sha256": "0a8cac771ca188eacc57e2c96c31f5611925c5ecedccb16b8c236d6c0d325112",
+					},
+				},
+				"cache-key": cacheKey,
+			}))
+
+			Expect(layer.LaunchEnv).To(Equal(packit.Environment{
+				"DOTNET_ROOT.override": filepath.Join(workingDir, ".dotnet_root"),
+			}))
+			Expect(layer.SharedEnv).To(Equal(packit.Environment{
+				"DOTNET_ASPNETCORE_VERSION.override": "2.5.1",
+				"DOTNET_ASPNETCORE_PATH.override":    filepath.Join(layersDir, "dotnet-core-aspnet", "shared", "Microsoft.AspNetCore.App"),
+			}))
+			Expect(filepath.Join(layersDir, "dotnet-core-aspnet", "aspnetcore.toml")).To(BeARegularFile())
+
+			Expect(layer.Build).To(BeFalse())
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.Cache).To(BeTrue())
+
+			Expect(result.Launch.BOM).To(HaveLen(1))
+			launchBOMEntry := result.Launch.BOM[0]
+			Expect(launchBOMEntry.Name).To(Equal("dotnet-aspnetcore"))
+			Expect(launchBOMEntry.Metadata).To(Equal(paketosbom.BOMMetadata{
+				Version: "dotnet-aspnetcore-dep-version",
+				Checksum: paketosbom.BOMChecksum{
+					Algorithm: paketosbom.SHA256,
+					Hash:      "dotnet-aspnetcore-dep-sha",
+				},
+				URI: "dotnet-aspnetcore-dep-uri",
+			}))
+
+			Expect(dependencyManager.GenerateBillOfMaterialsCall.Receives.Dependencies).To(Equal([]postal.Dependency{
+				{
+					ID:      "dotnet-aspnetcore",
+					Name:    ".NET Core ASPNet",
+					Version: "2.5.1",
+					SHA256:  "some-sha",
+				},
+			}))
+
+			Expect(symlinker.LinkCall.CallCount).To(Equal(1))
+			Expect(symlinker.LinkCall.Receives.DotnetRoot).To(Equal(filepath.Join(workingDir, ".dotnet_root")))
+			Expect(symlinker.LinkCall.Receives.LayerPath).To(Equal(filepath.Join(layersDir, "dotnet-core-aspnet")))
+
+			Expect(validator.ValidateCall.CallCount).To(Equal(1))
+
+			Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+
+			Expect(buffer.String()).To(ContainSubstring("Some Buildpack some-version"))
+			Expect(buffer.String()).To(ContainSubstring("Resolving .NET Core ASPNet version"))
+			Expect(buffer.String()).To(ContainSubstring("Selected .NET Core ASPNet version (using BP_DOTNET_FRAMEWORK_VERSION): "))
+			Expect(buffer.String()).To(ContainSubstring("Reusing cached layer"))
+			Expect(buffer.String()).ToNot(ContainSubstring("Executing build process"))
+		})
+
+		context("when the layer was built under different conditions", func() {
+			var buildContext packit.BuildContext
+
+			it.Before(func() {
+				buildContext = packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{
+								Name: "dotnet-aspnetcore",
+								Metadata: map[string]interface{}{
+									"version":        "2.5.x",
+									"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								},
+							},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				}
+			})
+
+			it("reinstalls when the stack changed", func() {
+				buildContext.Stack = "other-stack"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("Not reusing cached layer %s", filepath.Join(layersDir, "dotnet-core-aspnet")))
+				Expect(buffer.String()).To(ContainSubstring(`The target changed from "some-stack (%[1]s/%[2]s)" to "other-stack (%[1]s/%[2]s)"`, runtime.GOOS, runtime.GOARCH))
+			})
+
+			it("reinstalls when the buildpack version changed", func() {
+				buildContext.BuildpackInfo.Version = "other-version"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring(`The buildpack-version changed from "some-version" to "other-version"`))
+			})
+
+			it("reinstalls when the installed versions changed", func() {
+				dependencyManager.ResolveCall.Returns.Dependency.Version = "2.5.2"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("The installed versions changed from [2.5.1] to [2.5.2]"))
+			})
+
+			it("reinstalls when the checksum of the dependency changed", func() {
+				dependencyManager.ResolveCall.Returns.Dependency.SHA256 = "other-sha"
+
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("The checksums of the dependencies changed"))
+			})
+
+			context("when the link mode changed", func() {
+				it.Before(func() {
+					Expect(os.Setenv("BP_DOTNET_ROOT_LINK_MODE", "version")).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Unsetenv("BP_DOTNET_ROOT_LINK_MODE")).To(Succeed())
+				})
+
+				it("reinstalls", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+					Expect(buffer.String()).To(ContainSubstring(`The dotnet-root-link-mode changed from "framework" to "version"`))
+				})
+			})
+
+			context("when the layer has no cache key", func() {
+				it.Before(func() {
+					err := os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet.toml"), []byte("[metadata.dependency-shas]\n\"2.5.1\" = \"some-sha\"\n"), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("reinstalls", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+					Expect(buffer.String()).To(ContainSubstring("The layer has no cache key"))
+				})
+			})
+		})
+
+		context("when the cached layer does not match its content manifest", func() {
+			var buildContext packit.BuildContext
+
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet", "some-file"), []byte("other-content"), 0600)).To(Succeed())
+
+				buildContext = packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{
+								Name: "dotnet-aspnetcore",
+								Metadata: map[string]interface{}{
+									"version":        "2.5.x",
+									"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								},
+							},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				}
+			})
+
+			it("installs the dependency again", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(result.Layers[0].Metadata).To(Equal(map[string]interface{}{
+					"dependency-shas":  map[string]interface{}{"2.5.1": "some-sha"},
+					"content-manifest": dotnetcoreaspnet.ContentManifest{},
+					"cache-key":        cacheKey,
+				}))
+
+				Expect(buffer.String()).To(ContainSubstring("Cached layer %s failed verification", filepath.Join(layersDir, "dotnet-core-aspnet")))
+				Expect(buffer.String()).To(ContainSubstring("some-file has a size of 13 bytes, expected 12"))
+				Expect(buffer.String()).To(ContainSubstring("Executing build process"))
+			})
+		})
+
+		context("when a file of the cached layer is corrupted without changing its size", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet", "some-file"), []byte("some-CONTENT"), 0600)).To(Succeed())
+				Expect(os.Setenv("BP_DOTNET_CACHE_VERIFICATION", "full")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_CACHE_VERIFICATION")).To(Succeed())
+			})
+
+			it("installs the dependency again", func() {
+				_, err := build(packit.BuildContext{
+					WorkingDir: workingDir,
+					CNBPath:    cnbDir,
+					Stack:      "some-stack",
+					BuildpackInfo: packit.BuildpackInfo{
+						Name:    "Some Buildpack",
+						Version: "some-version",
+					},
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{
+								Name: "dotnet-aspnetcore",
+								Metadata: map[string]interface{}{
+									"version":        "2.5.x",
+									"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+								},
+							},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("some-file has a checksum of"))
+			})
+		})
+	})
+
+	context("when version-source of the selected entry is buildpack.yml", func() {
+		it.Before(func() {
+			entryResolver.ResolveCall.Returns.BuildpackPlanEntry = packit.BuildpackPlanEntry{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": "buildpack.yml",
+					"version":        "2.5.x",
+				},
+			}
+		})
+		it("chooses the specified version and emits a warning", func() {
+			_, err := build(packit.BuildContext{
+				WorkingDir: workingDir,
+				CNBPath:    cnbDir,
+				Stack:      "some-stack",
+				BuildpackInfo: packit.BuildpackInfo{
+					Name:    "Some Buildpack",
+					Version: "0.1.2",
+				},
+				Plan: packit.BuildpackPlan{
+					Entries: []packit.BuildpackPlanEntry{
+						{
+							Name: "dotnet-aspnetcore",
+							Metadata: map[string]interface{}{
+								"version-source": "buildpack.yml",
+								"version":        "2.5.x",
+							},
+						},
+					},
+				},
+				Layers: packit.Layers{Path: layersDir},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("Some Buildpack 0.1.2"))
+			Expect(buffer.String()).To(ContainSubstring("Resolving .NET Core ASPNet version"))
+			Expect(buffer.String()).To(ContainSubstring("Selected .NET Core ASPNet version (using buildpack.yml): "))
+			// v1.0.0 because that's the next major after input version v0.1.2
+			Expect(buffer.String()).To(ContainSubstring("WARNING: Setting the .NET Framework version through buildpack.yml will be deprecated soon in .NET Core ASPNet Buildpack v1.0.0."))
+			Expect(buffer.String()).To(ContainSubstring("Please specify the version through the $BP_DOTNET_FRAMEWORK_VERSION environment variable instead. See docs for more information."))
+			Expect(buffer.String()).To(ContainSubstring("Executing build process"))
+			Expect(buffer.String()).To(ContainSubstring("Configuring launch environment"))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the dependency cannot be resolved", func() {
+			it.Before(func() {
+				dependencyManager.ResolveCall.Returns.Error = errors.New("failed to resolve dependency")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("failed to resolve dependency"))
+			})
+		})
+
+		context("when the roll-forward policy is not supported", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_ROLL_FORWARD", "Sometimes")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_ROLL_FORWARD")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`unsupported roll-forward policy "Sometimes"`)))
+			})
+		})
+
+		context("when the dependency catalog can not be read", func() {
+			it.Before(func() {
+				catalog.DependenciesCall.Returns.Error = errors.New("failed to read catalog")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("failed to read catalog"))
+			})
+		})
+
+		context("when BP_DOTNET_AUTO_UPGRADE_PATCHES is not a boolean", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_AUTO_UPGRADE_PATCHES", "sometimes")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_AUTO_UPGRADE_PATCHES")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`invalid value for BP_DOTNET_AUTO_UPGRADE_PATCHES "sometimes"`)))
+			})
+		})
+
+		context("when BP_DOTNET_FAIL_ON_EOL is not a boolean", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_FAIL_ON_EOL", "sometimes")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_FAIL_ON_EOL")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`invalid value for BP_DOTNET_FAIL_ON_EOL "sometimes"`)))
+			})
+		})
+
+		context("when BP_DOTNET_EOL_WARNING_DAYS is not a number of days", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_EOL_WARNING_DAYS", "-1")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_EOL_WARNING_DAYS")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`invalid value for BP_DOTNET_EOL_WARNING_DAYS "-1"`)))
+			})
+		})
+
+		context("when the dotnet root strategy is not supported", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_ROOT_STRATEGY", "somewhere")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_ROOT_STRATEGY")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(ContainSubstring(`unsupported dotnet root strategy "somewhere"`)))
+			})
 		})
 
 		context("when the dotnet symlinker fails on a rebuild", func() {
 			it.Before(func() {
-				err := os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet.toml"), []byte("[metadata]\ndependency-sha = \"some-sha\"\n"), 0600)
+				err := os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet.toml"), []byte(fmt.Sprintf(`[metadata.dependency-shas]
+"2.5.1" = "some-sha"
+
+[metadata.content-manifest.some-file]
+size = 12
+sha256 = "0a8cac771ca188eacc57e2c96c31f5611925c5ecedccb16b8c236d6c0d325112"
+
+[metadata.cache-key]
+buildpack-version = %q
+target = %q
+dotnet-root-strategy = "working-dir"
+dotnet-root-link-mode = "framework"
+`, "", cacheKey["target"])), 0600)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(os.MkdirAll(filepath.Join(layersDir, "dotnet-core-aspnet"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet", "some-file"), []byte("some-content"), 0600)).To(Succeed())
 
 				dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
-					ID:     "dotnet-aspnetcore",
-					SHA256: "some-sha",
+					ID:      "dotnet-aspnetcore",
+					Version: "2.5.1",
+					SHA256:  "some-sha",
 				}
 
 				symlinker.LinkCall.Returns.Err = errors.New("symlinker error")
@@ -540,6 +2554,48 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the dependency cannot be downloaded", func() {
+			it.Before(func() {
+				downloadCache.FetchCall.Stub = nil
+				downloadCache.FetchCall.Returns.Error = errors.New("failed to fetch dependency")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("failed to fetch dependency"))
+				Expect(dependencyManager.DeliverCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when the download cache cannot be pruned", func() {
+			it.Before(func() {
+				downloadCache.PruneCall.Returns.Error = errors.New("failed to prune download cache")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("failed to prune download cache"))
+			})
+		})
+
 		context("when the dependency cannot be installed", func() {
 			it.Before(func() {
 				dependencyManager.DeliverCall.Returns.Error = errors.New("failed to install dependency")
@@ -560,6 +2616,74 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the delivered framework cannot be verified", func() {
+			it.Before(func() {
+				verifier.VerifyCall.Returns.Error = errors.New("failed to verify installation")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("failed to verify installation"))
+
+				Expect(symlinker.LinkCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when the installed frameworks cannot be recorded", func() {
+			it.Before(func() {
+				dependencyManager.DeliverCall.Stub = func(_ postal.Dependency, _, layerPath, _ string) error {
+					return os.MkdirAll(filepath.Join(layerPath, "aspnetcore.toml"), os.ModePerm)
+				}
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(ContainSubstring("failed to write installed frameworks")))
+			})
+		})
+
+		context("when BP_DOTNET_CACHE_VERIFICATION is not supported", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_CACHE_VERIFICATION", "sometimes")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_CACHE_VERIFICATION")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError(`unsupported cache verification mode "sometimes": must be one of "full" or "sample"`))
+			})
+		})
+
 		context("when the dotnet symlinker fails", func() {
 			it.Before(func() {
 				symlinker.LinkCall.Returns.Err = errors.New("symlinker error")
@@ -580,9 +2704,72 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
+		context("when the runtime is not compatible on a rebuild", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet.toml"), []byte(fmt.Sprintf(`[metadata.dependency-shas]
+"2.5.1" = "some-sha"
+
+[metadata.content-manifest.some-file]
+size = 12
+sha256 = "0a8cac771ca188eacc57e2c96c31f5611925c5ecedccb16b8c236d6c0d325112"
+
+[metadata.cache-key]
+buildpack-version = %q
+target = %q
+dotnet-root-strategy = "working-dir"
+dotnet-root-link-mode = "framework"
+`, "", cacheKey["target"])), 0600)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.MkdirAll(filepath.Join(layersDir, "dotnet-core-aspnet"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, "dotnet-core-aspnet", "some-file"), []byte("some-content"), 0600)).To(Succeed())
+
+				dependencyManager.ResolveCall.Returns.Dependency = postal.Dependency{
+					ID:      "dotnet-aspnetcore",
+					Version: "2.5.1",
+					SHA256:  "some-sha",
+				}
+
+				validator.ValidateCall.Returns.Error = errors.New("validator error")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("validator error"))
+			})
+		})
+
+		context("when the runtime is not compatible", func() {
+			it.Before(func() {
+				validator.ValidateCall.Returns.Error = errors.New("validator error")
+			})
+
+			it("returns an error", func() {
+				_, err := build(packit.BuildContext{
+					CNBPath: cnbDir,
+					Plan: packit.BuildpackPlan{
+						Entries: []packit.BuildpackPlanEntry{
+							{Name: "dotnet-aspnetcore"},
+						},
+					},
+					Layers: packit.Layers{Path: layersDir},
+					Stack:  "some-stack",
+				})
+				Expect(err).To(MatchError("validator error"))
+			})
+		})
+
 		context("when generating the SBOM returns an error", func() {
 			it.Before(func() {
-				sbomGenerator.GenerateFromDependencyCall.Returns.Error = errors.New("failed to generate SBOM")
+				sbomGenerator.GenerateFromDependenciesCall.Returns.Error = errors.New("failed to generate SBOM")
 			})
 
 			it("returns an error", func() {
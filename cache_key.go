@@ -0,0 +1,48 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// cacheKeyChanges describes how the dependencies and cache key of the layer
+// differ from the ones recorded in the metadata of the cached layer.
+func cacheKeyChanges(metadata map[string]interface{}, shas, cacheKey map[string]interface{}) []string {
+	var changes []string
+
+	cachedSHAs, _ := metadata["dependency-shas"].(map[string]interface{})
+	if !reflect.DeepEqual(cachedSHAs, shas) {
+		cachedVersions, versions := sortedKeys(cachedSHAs), sortedKeys(shas)
+		if reflect.DeepEqual(cachedVersions, versions) {
+			changes = append(changes, "The checksums of the dependencies changed")
+		} else {
+			changes = append(changes, fmt.Sprintf("The installed versions changed from [%s] to [%s]", strings.Join(cachedVersions, ", "), strings.Join(versions, ", ")))
+		}
+	}
+
+	cachedKey, ok := metadata["cache-key"].(map[string]interface{})
+	if !ok {
+		return append(changes, "The layer has no cache key")
+	}
+
+	for _, name := range sortedKeys(cacheKey) {
+		cached, _ := cachedKey[name].(string)
+		if cached != cacheKey[name] {
+			changes = append(changes, fmt.Sprintf("The %s changed from %q to %q", name, cached, cacheKey[name]))
+		}
+	}
+
+	return changes
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
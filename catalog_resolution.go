@@ -0,0 +1,79 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+var pessimisticPattern = regexp.MustCompile(`~>`)
+
+// resolveFromCatalog returns the latest dependency in the catalog that
+// satisfies the version constraint, interpreting it the way the dependency
+// manager does: an empty or "default" version selects the default version
+// declared in the buildpack.toml, the pessimistic operator (~>) is supported,
+// and a dependency built for specific stacks takes priority over one of the
+// same version that is built for any stack.
+func resolveFromCatalog(catalogDeps []CatalogDependency, id, version, defaultVersion string, target Target) (postal.Dependency, error) {
+	if version == "" || version == "default" {
+		version = "*"
+		if defaultVersion != "" {
+			version = defaultVersion
+		}
+	}
+
+	if pessimisticPattern.MatchString(version) {
+		version = pessimisticConstraint(version)
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return postal.Dependency{}, err
+	}
+
+	dependency, ok := latestDependency(catalogDeps, func(dependency CatalogDependency) bool {
+		return constraint.Check(semver.MustParse(dependency.Version))
+	})
+	if ok {
+		if specific, ok := latestDependency(catalogDeps, func(candidate CatalogDependency) bool {
+			return candidate.Version == dependency.Version && !containsString(candidate.Stacks, "*")
+		}); ok {
+			return specific.Dependency, nil
+		}
+
+		return dependency.Dependency, nil
+	}
+
+	var supported []string
+	for _, dependency := range catalogDeps {
+		supported = append(supported, dependency.Version)
+	}
+
+	return postal.Dependency{}, fmt.Errorf("failed to satisfy %q dependency version constraint for %s on %s: no compatible versions. Supported versions are: [%s]", version, id, target, strings.Join(supported, ", "))
+}
+
+// pessimisticConstraint converts a constraint using the pessimistic operator
+// into a tilde range when it names a patch version, which allows later
+// patches, and into a caret range otherwise, which allows later minor
+// versions.
+func pessimisticConstraint(version string) string {
+	version = strings.TrimSpace(pessimisticPattern.ReplaceAllString(version, ""))
+	if len(strings.Split(version, ".")) == 3 {
+		return "~" + version
+	}
+
+	return "^" + version
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
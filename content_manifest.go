@@ -0,0 +1,197 @@
+package dotnetcoreaspnet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+const (
+	CacheVerificationFull   = "full"
+	CacheVerificationSample = "sample"
+)
+
+// manifestSampleSize is the number of files whose content is hashed when a
+// manifest is verified by sampling.
+const manifestSampleSize = 32
+
+// ManifestEntry records the size and checksum of a file in a layer.
+type ManifestEntry struct {
+	Size   int64  `toml:"size"`
+	SHA256 string `toml:"sha256"`
+}
+
+// ContentManifest maps the path of each file in a layer, relative to the
+// layer, to its size and checksum.
+type ContentManifest map[string]ManifestEntry
+
+// NewContentManifest records every regular file in the given directory.
+func NewContentManifest(dir string) (ContentManifest, error) {
+	manifest := ContentManifest{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		manifest[filepath.ToSlash(rel)] = ManifestEntry{Size: info.Size(), SHA256: sum}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Verify checks that every file in the manifest is present in the given
+// directory with its recorded size. In full mode, the checksum of every file
+// is verified; in sample mode, only the checksums of a random sample of the
+// files are.
+func (m ContentManifest) Verify(dir, mode string) error {
+	var paths []string
+	for path := range m {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%s is missing", path)
+			}
+
+			return err
+		}
+
+		if info.Size() != m[path].Size {
+			return fmt.Errorf("%s has a size of %d bytes, expected %d", path, info.Size(), m[path].Size)
+		}
+	}
+
+	if mode == CacheVerificationSample && len(paths) > manifestSampleSize {
+		random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+		var sample []string
+		for _, i := range random.Perm(len(paths))[:manifestSampleSize] {
+			sample = append(sample, paths[i])
+		}
+		paths = sample
+	}
+
+	for _, path := range paths {
+		sum, err := fileSHA256(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+
+		if sum != m[path].SHA256 {
+			return fmt.Errorf("%s has a checksum of %s, expected %s", path, sum, m[path].SHA256)
+		}
+	}
+
+	return nil
+}
+
+// contentManifestFromMetadata reads a manifest back from the layer metadata
+// it was stored in.
+func contentManifestFromMetadata(metadata interface{}) (ContentManifest, bool) {
+	switch m := metadata.(type) {
+	case ContentManifest:
+		return m, true
+	case map[string]interface{}:
+		manifest := ContentManifest{}
+		for path, value := range m {
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			size, ok := fields["size"].(int64)
+			if !ok {
+				return nil, false
+			}
+
+			sum, ok := fields["sha256"].(string)
+			if !ok {
+				return nil, false
+			}
+
+			manifest[path] = ManifestEntry{Size: size, SHA256: sum}
+		}
+
+		return manifest, true
+	}
+
+	return nil, false
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyCachedLayer checks the content of a cached layer against the
+// manifest recorded when it was installed.
+func verifyCachedLayer(layer packit.Layer, mode string) error {
+	manifest, ok := contentManifestFromMetadata(layer.Metadata["content-manifest"])
+	if !ok {
+		return errors.New("the layer has no valid content manifest")
+	}
+
+	return manifest.Verify(layer.Path, mode)
+}
+
+func cacheVerificationMode() (string, error) {
+	mode, ok := os.LookupEnv("BP_DOTNET_CACHE_VERIFICATION")
+	if !ok || mode == "" {
+		return CacheVerificationSample, nil
+	}
+
+	if mode != CacheVerificationFull && mode != CacheVerificationSample {
+		return "", fmt.Errorf("unsupported cache verification mode %q: must be one of %q or %q", mode, CacheVerificationFull, CacheVerificationSample)
+	}
+
+	return mode, nil
+}
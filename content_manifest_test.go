@@ -0,0 +1,128 @@
+package dotnetcoreaspnet_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testContentManifest(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath string
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "some-file"), []byte("some-content"), 0600)).To(Succeed())
+		Expect(os.Symlink("some-file", filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "some-link"))).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	context("NewContentManifest", func() {
+		it("records the size and checksum of every file", func() {
+			manifest, err := dotnetcoreaspnet.NewContentManifest(layerPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifest).To(Equal(dotnetcoreaspnet.ContentManifest{
+				"shared/Microsoft.AspNetCore.App/6.0.13/some-file": {
+					Size:   12,
+					SHA256: "0a8cac771ca188eacc57e2c96c31f5611925c5ecedccb16b8c236d6c0d325112",
+				},
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the directory does not exist", func() {
+				it("returns an error", func() {
+					_, err := dotnetcoreaspnet.NewContentManifest(filepath.Join(layerPath, "missing"))
+					Expect(err).To(MatchError(ContainSubstring("failed to create content manifest")))
+				})
+			})
+		})
+	})
+
+	context("Verify", func() {
+		var manifest dotnetcoreaspnet.ContentManifest
+
+		it.Before(func() {
+			var err error
+			manifest, err = dotnetcoreaspnet.NewContentManifest(layerPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.WriteFile(filepath.Join(layerPath, "some-new-file"), nil, 0600)).To(Succeed())
+		})
+
+		it("accepts a layer that matches the manifest", func() {
+			Expect(manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationFull)).To(Succeed())
+			Expect(manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationSample)).To(Succeed())
+		})
+
+		context("when a file is missing", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "some-file"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationSample)
+				Expect(err).To(MatchError("shared/Microsoft.AspNetCore.App/6.0.13/some-file is missing"))
+			})
+		})
+
+		context("when a file is truncated", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "some-file"), []byte("some"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationSample)
+				Expect(err).To(MatchError("shared/Microsoft.AspNetCore.App/6.0.13/some-file has a size of 4 bytes, expected 12"))
+			})
+		})
+
+		context("when the content of a file changes", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "some-file"), []byte("some-CONTENT"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationFull)
+				Expect(err).To(MatchError(ContainSubstring("shared/Microsoft.AspNetCore.App/6.0.13/some-file has a checksum of")))
+			})
+		})
+
+		context("when the manifest lists more files than are sampled", func() {
+			it.Before(func() {
+				for i := 0; i < 100; i++ {
+					path := filepath.Join(layerPath, fmt.Sprintf("file-%d", i))
+					Expect(os.WriteFile(path, []byte(fmt.Sprintf("content-%d", i)), 0600)).To(Succeed())
+				}
+
+				var err error
+				manifest, err = dotnetcoreaspnet.NewContentManifest(layerPath)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("verifies the sizes of every file", func() {
+				Expect(manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationSample)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(layerPath, "file-42"), []byte("content-420"), 0600)).To(Succeed())
+				err := manifest.Verify(layerPath, dotnetcoreaspnet.CacheVerificationSample)
+				Expect(err).To(MatchError("file-42 has a size of 11 bytes, expected 10"))
+			})
+		})
+	})
+}
@@ -0,0 +1,200 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// CatalogDependency is a dependency as listed in the buildpack.toml, including
+// the metadata that postal.Dependency does not carry.
+type CatalogDependency struct {
+	postal.Dependency
+
+	Arch     string   `toml:"arch"`
+	Channel  string   `toml:"channel"`
+	Distros  []Distro `toml:"distros"`
+	OS       string   `toml:"os"`
+	Security bool     `toml:"security"`
+}
+
+// Distro is an operating system distribution that a dependency is built for.
+type Distro struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+}
+
+type DependencyCatalog struct{}
+
+func NewDependencyCatalog() DependencyCatalog {
+	return DependencyCatalog{}
+}
+
+// Dependencies returns the dependencies with the given id, sorted by
+// ascending version.
+func (c DependencyCatalog) Dependencies(path, id string) ([]CatalogDependency, error) {
+	var buildpack struct {
+		Metadata struct {
+			Dependencies []CatalogDependency `toml:"dependencies"`
+		} `toml:"metadata"`
+	}
+
+	_, err := toml.DecodeFile(path, &buildpack)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var dependencies []CatalogDependency
+	for _, dependency := range buildpack.Metadata.Dependencies {
+		if dependency.ID != id {
+			continue
+		}
+
+		_, err := semver.NewVersion(dependency.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse version of %s dependency: %w", dependency.ID, err)
+		}
+
+		dependencies = append(dependencies, dependency)
+	}
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return semver.MustParse(dependencies[i].Version).LessThan(semver.MustParse(dependencies[j].Version))
+	})
+
+	return dependencies, nil
+}
+
+// DefaultVersion returns the version constraint that the buildpack.toml
+// declares as the default for the dependency with the given id, or an empty
+// string when it declares none.
+func (c DependencyCatalog) DefaultVersion(path, id string) (string, error) {
+	var buildpack struct {
+		Metadata struct {
+			DefaultVersions map[string]string `toml:"default-versions"`
+		} `toml:"metadata"`
+	}
+
+	_, err := toml.DecodeFile(path, &buildpack)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return buildpack.Metadata.DefaultVersions[id], nil
+}
+
+// SupportChannel returns the support channel of the dependency, either "lts"
+// or "sts". Dependencies that do not declare their channel are classified
+// by the .NET release cadence, in which even major versions are LTS releases.
+func (d CatalogDependency) SupportChannel() string {
+	if d.Channel != "" {
+		return strings.ToLower(d.Channel)
+	}
+
+	if semver.MustParse(d.Version).Major()%2 == 0 {
+		return "lts"
+	}
+
+	return "sts"
+}
+
+// SupportsArch returns whether the dependency is built for the given
+// architecture. Dependencies that do not declare their architecture are
+// assumed to support any architecture.
+func (d CatalogDependency) SupportsArch(arch string) bool {
+	return d.Arch == "" || normalizeArch(d.Arch) == normalizeArch(arch)
+}
+
+// normalizeArch maps the common aliases of an architecture to its Go name.
+func normalizeArch(arch string) string {
+	switch strings.ToLower(arch) {
+	case "x64", "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	}
+
+	return strings.ToLower(arch)
+}
+
+// SupportsPlatform returns whether the dependency can be installed on the
+// operating system and distribution of the given target. On platforms that
+// provide a distribution, dependencies are matched on the distributions they
+// declare. Dependencies that declare none are matched on the distributions of
+// the well-known stacks they list, and otherwise on the stack itself.
+// Dependencies that declare neither are assumed to support any platform.
+func (d CatalogDependency) SupportsPlatform(target Target) bool {
+	if d.OS != "" && target.OS != "" && !strings.EqualFold(d.OS, target.OS) {
+		return false
+	}
+
+	if target.DistroName != "" {
+		distros := d.Distros
+		if len(distros) == 0 {
+			distros = stackDistros(d.Stacks)
+		}
+
+		if len(distros) > 0 {
+			for _, distro := range distros {
+				if strings.EqualFold(distro.Name, target.DistroName) && (distro.Version == "" || distro.Version == target.DistroVersion) {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+
+	if len(d.Stacks) == 0 && len(d.Distros) == 0 {
+		return true
+	}
+
+	for _, stack := range d.Stacks {
+		if stack == target.Stack || stack == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// knownStackDistros maps the stack IDs that dependencies are commonly built
+// for to the distribution they are based on.
+var knownStackDistros = map[string]Distro{
+	"io.buildpacks.stacks.bionic": {Name: "ubuntu", Version: "18.04"},
+	"io.buildpacks.stacks.focal":  {Name: "ubuntu", Version: "20.04"},
+	"io.buildpacks.stacks.jammy":  {Name: "ubuntu", Version: "22.04"},
+	"io.buildpacks.stacks.noble":  {Name: "ubuntu", Version: "24.04"},
+}
+
+// stackDistros returns the distributions of the given stacks, which is empty
+// unless every stack is a well-known one.
+func stackDistros(stacks []string) []Distro {
+	var distros []Distro
+	for _, stack := range stacks {
+		distro, ok := knownStackDistros[stack]
+		if !ok {
+			return nil
+		}
+
+		distros = append(distros, distro)
+	}
+
+	return distros
+}
+
+// latestDependency returns the latest of the given dependencies, which are
+// sorted by ascending version as returned by Dependencies, that matches.
+func latestDependency(dependencies []CatalogDependency, match func(CatalogDependency) bool) (CatalogDependency, bool) {
+	for i := len(dependencies) - 1; i >= 0; i-- {
+		if match(dependencies[i]) {
+			return dependencies[i], true
+		}
+	}
+
+	return CatalogDependency{}, false
+}
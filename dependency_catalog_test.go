@@ -0,0 +1,232 @@
+package dotnetcoreaspnet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDependencyCatalog(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cnbDir  string
+		catalog dotnetcoreaspnet.DependencyCatalog
+	)
+
+	it.Before(func() {
+		var err error
+		cnbDir, err = os.MkdirTemp("", "cnb")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[metadata]
+  [metadata.default-versions]
+    dotnet-aspnetcore = "6.0.*"
+
+  [[metadata.dependencies]]
+    deprecation_date = "2024-11-12T00:00:00Z"
+    id = "dotnet-aspnetcore"
+    name = ".NET Core ASPNet"
+    arch = "arm64"
+    channel = "lts"
+    sha256 = "some-sha"
+    security = true
+    stacks = ["some-stack"]
+    version = "6.0.13"
+
+    [[metadata.dependencies.distros]]
+      name = "ubuntu"
+      version = "22.04"
+
+  [[metadata.dependencies]]
+    id = "dotnet-aspnetcore"
+    name = ".NET Core ASPNet"
+    sha256 = "other-sha"
+    stacks = ["*"]
+    version = "6.0.12"
+
+  [[metadata.dependencies]]
+    id = "dotnet-aspnetcore"
+    name = ".NET Core ASPNet"
+    stacks = ["other-stack"]
+    version = "7.0.1"
+
+  [[metadata.dependencies]]
+    id = "dotnet-runtime"
+    stacks = ["some-stack"]
+    version = "6.0.13"
+`), 0600)
+		Expect(err).NotTo(HaveOccurred())
+
+		catalog = dotnetcoreaspnet.NewDependencyCatalog()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(cnbDir)).To(Succeed())
+	})
+
+	context("Dependencies", func() {
+		it("returns the dependencies for the id in version order", func() {
+			dependencies, err := catalog.Dependencies(filepath.Join(cnbDir, "buildpack.toml"), "dotnet-aspnetcore")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dependencies).To(Equal([]dotnetcoreaspnet.CatalogDependency{
+				{
+					Dependency: postal.Dependency{
+						ID:      "dotnet-aspnetcore",
+						Name:    ".NET Core ASPNet",
+						Version: "6.0.12",
+						SHA256:  "other-sha",
+						Stacks:  []string{"*"},
+					},
+				},
+				{
+					Dependency: postal.Dependency{
+						ID:              "dotnet-aspnetcore",
+						Name:            ".NET Core ASPNet",
+						Version:         "6.0.13",
+						SHA256:          "some-sha",
+						Stacks:          []string{"some-stack"},
+						DeprecationDate: time.Date(2024, 11, 12, 0, 0, 0, 0, time.UTC),
+					},
+					Arch:     "arm64",
+					Channel:  "lts",
+					Distros:  []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "22.04"}},
+					Security: true,
+				},
+				{
+					Dependency: postal.Dependency{
+						ID:      "dotnet-aspnetcore",
+						Name:    ".NET Core ASPNet",
+						Version: "7.0.1",
+						Stacks:  []string{"other-stack"},
+					},
+				},
+			}))
+		})
+
+		context("SupportChannel", func() {
+			it("returns the declared channel", func() {
+				Expect(dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Version: "7.0.1"}, Channel: "LTS"}.SupportChannel()).To(Equal("lts"))
+			})
+
+			it("classifies dependencies without a channel by their major version", func() {
+				Expect(dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Version: "6.0.13"}}.SupportChannel()).To(Equal("lts"))
+				Expect(dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Version: "7.0.1"}}.SupportChannel()).To(Equal("sts"))
+			})
+		})
+
+		context("SupportsArch", func() {
+			it("matches the declared architecture and its aliases", func() {
+				dependency := dotnetcoreaspnet.CatalogDependency{Arch: "x64"}
+				Expect(dependency.SupportsArch("amd64")).To(BeTrue())
+				Expect(dependency.SupportsArch("arm64")).To(BeFalse())
+			})
+
+			it("supports any architecture when none is declared", func() {
+				Expect(dotnetcoreaspnet.CatalogDependency{}.SupportsArch("arm64")).To(BeTrue())
+			})
+		})
+
+		context("SupportsPlatform", func() {
+			it("matches the stack on platforms that do not provide a distribution", func() {
+				dependency := dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Stacks: []string{"some-stack"}}}
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{Stack: "some-stack"})).To(BeTrue())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{Stack: "other-stack"})).To(BeFalse())
+			})
+
+			it("matches the distribution on platforms that provide one", func() {
+				dependency := dotnetcoreaspnet.CatalogDependency{
+					Dependency: postal.Dependency{Stacks: []string{"some-stack"}},
+					Distros:    []dotnetcoreaspnet.Distro{{Name: "ubuntu", Version: "22.04"}},
+					OS:         "linux",
+				}
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{OS: "linux", DistroName: "ubuntu", DistroVersion: "22.04"})).To(BeTrue())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{OS: "linux", DistroName: "ubuntu", DistroVersion: "18.04", Stack: "some-stack"})).To(BeFalse())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{OS: "windows", DistroName: "ubuntu", DistroVersion: "22.04"})).To(BeFalse())
+			})
+
+			it("falls back to the stack for dependencies without distributions", func() {
+				dependency := dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Stacks: []string{"some-stack"}}}
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "ubuntu", DistroVersion: "22.04", Stack: "some-stack"})).To(BeTrue())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "ubuntu", DistroVersion: "22.04"})).To(BeFalse())
+			})
+
+			it("derives the distributions of dependencies without distributions from well-known stacks", func() {
+				dependency := dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Stacks: []string{"io.buildpacks.stacks.bionic", "io.buildpacks.stacks.jammy"}}}
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "ubuntu", DistroVersion: "22.04"})).To(BeTrue())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "ubuntu", DistroVersion: "18.04"})).To(BeTrue())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "ubuntu", DistroVersion: "20.04"})).To(BeFalse())
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "alpine", DistroVersion: "3.18"})).To(BeFalse())
+			})
+
+			it("matches any distribution for dependencies built for any stack", func() {
+				dependency := dotnetcoreaspnet.CatalogDependency{Dependency: postal.Dependency{Stacks: []string{"*"}}}
+				Expect(dependency.SupportsPlatform(dotnetcoreaspnet.Target{DistroName: "ubuntu", DistroVersion: "22.04"})).To(BeTrue())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the buildpack.toml can not be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := catalog.Dependencies(filepath.Join(cnbDir, "buildpack.toml"), "dotnet-aspnetcore")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+
+			context("when a dependency version is not valid", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte(`
+[[metadata.dependencies]]
+  id = "dotnet-aspnetcore"
+  stacks = ["some-stack"]
+  version = "not-a-version"
+`), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := catalog.Dependencies(filepath.Join(cnbDir, "buildpack.toml"), "dotnet-aspnetcore")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse version of dotnet-aspnetcore dependency")))
+				})
+			})
+		})
+	})
+
+	context("DefaultVersion", func() {
+		it("returns the default version for the id", func() {
+			version, err := catalog.DefaultVersion(filepath.Join(cnbDir, "buildpack.toml"), "dotnet-aspnetcore")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("6.0.*"))
+		})
+
+		it("returns an empty version when none is declared for the id", func() {
+			version, err := catalog.DefaultVersion(filepath.Join(cnbDir, "buildpack.toml"), "dotnet-runtime")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(BeEmpty())
+		})
+
+		context("failure cases", func() {
+			context("when the buildpack.toml can not be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cnbDir, "buildpack.toml"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := catalog.DefaultVersion(filepath.Join(cnbDir, "buildpack.toml"), "dotnet-aspnetcore")
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package dotnetcoreaspnet
+
+import (
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/pkg"
+	syftsbom "github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/sbom"
+)
+
+// FrameworkMetadataType is the type of the metadata that the SBOM records
+// for prerelease versions of the framework.
+const FrameworkMetadataType pkg.MetadataType = "DotnetFrameworkMetadata"
+
+// FrameworkMetadata marks a package in the SBOM as a prerelease version of
+// the framework, without changing the name, version, CPEs or PURL that
+// identify it. In CycloneDX it is recorded as the syft:metadata:prerelease
+// property.
+type FrameworkMetadata struct {
+	Prerelease bool `json:"prerelease" cyclonedx:"prerelease"`
+}
+
+// GenerateFromDependencies returns the SBOM of the given dependencies
+// installed at path. Like sbom.GenerateFromDependency does for a single
+// dependency, it lists a package for each dependency along with its CPEs,
+// PURL and licenses. Prerelease versions are marked with FrameworkMetadata.
+func GenerateFromDependencies(dependencies []postal.Dependency, path string) (sbom.SBOM, error) {
+	var packages []pkg.Package
+	for _, dependency := range dependencies {
+		//nolint Ignore SA1019, informed usage of deprecated package
+		cpeStrings := dependency.CPEs
+		if len(cpeStrings) == 0 {
+			//nolint Ignore SA1019, informed usage of deprecated package
+			cpeStrings = []string{dependency.CPE}
+		}
+
+		var cpes []cpe.CPE
+		for _, cpeString := range cpeStrings {
+			if cpeString == "" {
+				cpeString = sbom.UnknownCPE
+			}
+
+			c, err := cpe.New(cpeString)
+			if err != nil {
+				return sbom.SBOM{}, err
+			}
+			cpes = append(cpes, c)
+		}
+
+		p := pkg.Package{
+			Name:     dependency.Name,
+			Version:  dependency.Version,
+			Licenses: dependency.Licenses,
+			CPEs:     cpes,
+			PURL:     dependency.PURL,
+		}
+
+		if isPrerelease(dependency.Version) {
+			p.MetadataType = FrameworkMetadataType
+			p.Metadata = FrameworkMetadata{Prerelease: true}
+		}
+
+		packages = append(packages, p)
+	}
+
+	return sbom.NewSBOM(syftsbom.SBOM{
+		Artifacts: syftsbom.Artifacts{
+			PackageCatalog: pkg.NewCatalog(packages...),
+		},
+		Source: source.Metadata{
+			Scheme: source.DirectoryScheme,
+			Path:   path,
+		},
+	}), nil
+}
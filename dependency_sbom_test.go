@@ -0,0 +1,129 @@
+package dotnetcoreaspnet_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/sbom"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDependencySBOM(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("GenerateFromDependencies", func() {
+		it("lists each dependency with its CPEs, PURL and licenses", func() {
+			bom, err := dotnetcoreaspnet.GenerateFromDependencies([]postal.Dependency{
+				{
+					ID:       "dotnet-aspnetcore",
+					Name:     ".NET Core ASPNet",
+					Version:  "6.0.13",
+					CPEs:     []string{"cpe:2.3:a:microsoft:asp.net_core:6.0.13:*:*:*:*:*:*:*"},
+					PURL:     "pkg:generic/dotnet-aspnetcore@6.0.13",
+					Licenses: []string{"MIT"},
+				},
+				{
+					ID:       "dotnet-aspnetcore",
+					Name:     ".NET Core ASPNet",
+					Version:  "7.0.2",
+					CPE:      "cpe:2.3:a:microsoft:asp.net_core:7.0.2:*:*:*:*:*:*:*",
+					PURL:     "pkg:generic/dotnet-aspnetcore@7.0.2",
+					Licenses: []string{"Apache-2.0"},
+				},
+			}, "some-layer-path")
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := io.ReadAll(sbom.NewFormattedReader(bom, sbom.CycloneDXFormat))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(content)).To(ContainSubstring(`"version": "6.0.13"`))
+			Expect(string(content)).To(ContainSubstring(`"cpe": "cpe:2.3:a:microsoft:asp.net_core:6.0.13:*:*:*:*:*:*:*"`))
+			Expect(string(content)).To(ContainSubstring(`"purl": "pkg:generic/dotnet-aspnetcore@6.0.13"`))
+			Expect(string(content)).To(ContainSubstring(`"id": "MIT"`))
+
+			Expect(string(content)).To(ContainSubstring(`"version": "7.0.2"`))
+			Expect(string(content)).To(ContainSubstring(`"cpe": "cpe:2.3:a:microsoft:asp.net_core:7.0.2:*:*:*:*:*:*:*"`))
+			Expect(string(content)).To(ContainSubstring(`"purl": "pkg:generic/dotnet-aspnetcore@7.0.2"`))
+			Expect(string(content)).To(ContainSubstring(`"id": "Apache-2.0"`))
+		})
+
+		context("when a dependency is a prerelease", func() {
+			it("marks the prerelease without changing its name or version", func() {
+				bom, err := dotnetcoreaspnet.GenerateFromDependencies([]postal.Dependency{
+					{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "7.0.2", PURL: "pkg:generic/dotnet-aspnetcore@7.0.2"},
+					{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "8.0.0-rc.2", PURL: "pkg:generic/dotnet-aspnetcore@8.0.0-rc.2"},
+				}, "some-layer-path")
+				Expect(err).NotTo(HaveOccurred())
+
+				var output struct {
+					Components []struct {
+						Name       string `json:"name"`
+						Version    string `json:"version"`
+						PURL       string `json:"purl"`
+						Properties []struct {
+							Name  string `json:"name"`
+							Value string `json:"value"`
+						} `json:"properties"`
+					} `json:"components"`
+				}
+				Expect(json.NewDecoder(sbom.NewFormattedReader(bom, sbom.CycloneDXFormat)).Decode(&output)).To(Succeed())
+				Expect(output.Components).To(HaveLen(2))
+
+				for _, component := range output.Components {
+					Expect(component.Name).To(Equal(".NET Core ASPNet"))
+
+					var prerelease string
+					for _, property := range component.Properties {
+						if property.Name == "syft:metadata:prerelease" {
+							prerelease = property.Value
+						}
+					}
+
+					switch component.Version {
+					case "8.0.0-rc.2":
+						Expect(component.PURL).To(Equal("pkg:generic/dotnet-aspnetcore@8.0.0-rc.2"))
+						Expect(prerelease).To(Equal("true"))
+					default:
+						Expect(component.PURL).To(Equal("pkg:generic/dotnet-aspnetcore@7.0.2"))
+						Expect(prerelease).To(BeEmpty())
+					}
+				}
+
+				for _, format := range []sbom.Format{sbom.SPDXFormat, sbom.SyftFormat} {
+					content, err := io.ReadAll(sbom.NewFormattedReader(bom, format))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(ContainSubstring("8.0.0-rc.2"))
+				}
+			})
+		})
+
+		context("when a dependency declares no CPE", func() {
+			it("lists the dependency with an unknown CPE", func() {
+				bom, err := dotnetcoreaspnet.GenerateFromDependencies([]postal.Dependency{
+					{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13"},
+				}, "some-layer-path")
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := io.ReadAll(sbom.NewFormattedReader(bom, sbom.CycloneDXFormat))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring(`"cpe": "%s"`, sbom.UnknownCPE))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when a CPE is malformed", func() {
+				it("returns an error", func() {
+					_, err := dotnetcoreaspnet.GenerateFromDependencies([]postal.Dependency{
+						{ID: "dotnet-aspnetcore", Name: ".NET Core ASPNet", Version: "6.0.13", CPEs: []string{"not-a-cpe"}},
+					}, "some-layer-path")
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+	})
+}
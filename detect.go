@@ -1,8 +1,12 @@
 package dotnetcoreaspnet
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/paketo-buildpacks/packit/v2"
 )
@@ -12,15 +16,60 @@ type VersionParser interface {
 	ParseVersion(path string) (version string, err error)
 }
 
-func Detect(buildpackYMLParser VersionParser) packit.DetectFunc {
+//go:generate faux --interface ConfigParser --output fakes/config_parser.go
+type ConfigParser interface {
+	Parse(glob string) (RuntimeConfig, error)
+}
+
+//go:generate faux --interface ProjectParser --output fakes/project_parser.go
+type ProjectParser interface {
+	FindProjectFile(root string) (string, error)
+	ParseVersion(path string) (version string, err error)
+}
+
+func Detect(buildpackYMLParser VersionParser, runtimeConfigParser ConfigParser, projectParser ProjectParser) packit.DetectFunc {
 	return func(context packit.DetectContext) (packit.DetectResult, error) {
-		var requirements = []packit.BuildPlanRequirement{
-			{
-				Name: "dotnet-runtime",
-				Metadata: map[string]interface{}{
-					"build": true,
-				},
-			},
+		config, err := runtimeConfigParser.Parse(filepath.Join(context.WorkingDir, "*.runtimeconfig.json"))
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
+
+		// self-contained apps carry their own copy of the framework and host, so
+		// installing the shared framework would be of no use
+		selfContained := config.SelfContained
+		if !selfContained {
+			_, err = os.Stat(filepath.Join(context.WorkingDir, "libhostfxr.so"))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return packit.DetectResult{}, err
+			}
+			selfContained = err == nil
+		}
+
+		if selfContained {
+			return packit.DetectResult{}, packit.Fail.WithMessage("application is self-contained: it provides its own ASP.NET Core framework")
+		}
+
+		var requirements []packit.BuildPlanRequirement
+
+		// check if BP_DOTNET_ASPNETCORE_VERSIONS is set, which lists the
+		// versions to install side-by-side. Unlike BP_DOTNET_FRAMEWORK_VERSION,
+		// it is not read by the dotnet-core-runtime buildpack, which only
+		// accepts a single version.
+		if versions, ok := os.LookupEnv("BP_DOTNET_ASPNETCORE_VERSIONS"); ok {
+			for _, version := range strings.Split(versions, ",") {
+				version = strings.TrimSpace(version)
+				if version == "" {
+					continue
+				}
+
+				requirements = append(requirements, packit.BuildPlanRequirement{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+						"version":        version,
+					},
+				})
+			}
 		}
 
 		// check if BP_DOTNET_FRAMEWORK_VERSION is set
@@ -50,13 +99,150 @@ func Detect(buildpackYMLParser VersionParser) packit.DetectFunc {
 			})
 		}
 
+		// check if the version can be inferred from a project file
+		projectFile, err := projectParser.FindProjectFile(context.WorkingDir)
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
+
+		if projectFile != "" {
+			version, err := projectParser.ParseVersion(projectFile)
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+
+			if version != "" {
+				requirements = append(requirements, packit.BuildPlanRequirement{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": filepath.Base(projectFile),
+						"version":        version,
+					},
+				})
+			}
+		}
+
+		// check if the version is set in a *.runtimeconfig.json
+		if config.ASPNETVersion != "" {
+			metadata := map[string]interface{}{
+				"version-source": "runtimeconfig.json",
+				"version":        config.ASPNETVersion,
+			}
+
+			if config.RollForward != "" {
+				metadata["roll-forward"] = config.RollForward
+			}
+
+			requirements = append(requirements, packit.BuildPlanRequirement{
+				Name:     "dotnet-aspnetcore",
+				Metadata: metadata,
+			})
+		}
+
+		runtime, err := runtimeRequirements(requirements)
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
+
 		return packit.DetectResult{
 			Plan: packit.BuildPlan{
 				Provides: []packit.BuildPlanProvision{
 					{Name: "dotnet-aspnetcore"},
 				},
-				Requires: requirements,
+				Requires: append(runtime, requirements...),
 			},
 		}, nil
 	}
 }
+
+// runtimeRequirements returns the dotnet-runtime requirements for the
+// releases of the dotnet-aspnetcore versions that Build installs. The
+// requirements are appended in order of priority, so unless RUNTIME_VERSION
+// overrides them, those are the versions requested through the source of the
+// first requirement. A release is only required when Build is certain to
+// install a version of it; otherwise the runtime is left to select its own
+// version.
+func runtimeRequirements(aspnet []packit.BuildPlanRequirement) ([]packit.BuildPlanRequirement, error) {
+	var selected []packit.BuildpackPlanEntry
+	for _, requirement := range aspnet {
+		metadata := requirement.Metadata.(map[string]interface{})
+		if len(selected) > 0 && metadata["version-source"] != selected[0].Metadata["version-source"] {
+			break
+		}
+
+		selected = append(selected, packit.BuildpackPlanEntry{Name: requirement.Name, Metadata: metadata})
+	}
+
+	ignoreRuntimeVersion, err := boolEnv("BP_DOTNET_IGNORE_RUNTIME_VERSION")
+	if err != nil {
+		return nil, err
+	}
+
+	if version, ok := os.LookupEnv("RUNTIME_VERSION"); ok && !ignoreRuntimeVersion {
+		selected = []packit.BuildpackPlanEntry{
+			{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": "RUNTIME_VERSION",
+					"version":        version,
+				},
+			},
+		}
+	}
+
+	var requirements []packit.BuildPlanRequirement
+	forwarded := map[string]bool{}
+	for _, entry := range selected {
+		requested, _ := entry.Metadata["version"].(string)
+		version, ok := runtimeVersion(requested)
+		if !ok || forwarded[version] {
+			continue
+		}
+
+		// exact versions may be rolled forward onto another release by the
+		// policies other than Disable and LatestPatch
+		policy, _, err := rollForwardPolicy(entry)
+		if err != nil {
+			continue
+		}
+
+		if exactVersionPattern.MatchString(requested) && policy != "" && policy != RollForwardDisable && policy != RollForwardLatestPatch {
+			continue
+		}
+
+		forwarded[version] = true
+		requirements = append(requirements, packit.BuildPlanRequirement{
+			Name: "dotnet-runtime",
+			Metadata: map[string]interface{}{
+				"build":          true,
+				"version":        version,
+				"version-source": entry.Metadata["version-source"],
+			},
+		})
+	}
+
+	if len(requirements) == 0 {
+		requirements = append(requirements, packit.BuildPlanRequirement{
+			Name: "dotnet-runtime",
+			Metadata: map[string]interface{}{
+				"build": true,
+			},
+		})
+	}
+
+	return requirements, nil
+}
+
+// runtimeVersion returns the dotnet-runtime version constraint that matches
+// the release of the given dotnet-aspnetcore version. The ASP.NET Core and
+// .NET runtime patch versions are not guaranteed to be in lockstep, so only
+// the major and minor version are forwarded. Versions that do not identify a
+// release, such as aliases or major versions, are not forwarded.
+func runtimeVersion(version string) (string, bool) {
+	matches := regexp.MustCompile(`^(\d+)\.(\d+)(\.|$)`).FindStringSubmatch(version)
+	if matches == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s.%s.*", matches[1], matches[2]), true
+}
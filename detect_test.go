@@ -3,6 +3,7 @@ package dotnetcoreaspnet_test
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
 	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
@@ -17,15 +18,19 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect = NewWithT(t).Expect
 
-		workingDir         string
-		buildpackYMLParser *fakes.VersionParser
-		detect             packit.DetectFunc
+		workingDir          string
+		buildpackYMLParser  *fakes.VersionParser
+		runtimeConfigParser *fakes.ConfigParser
+		projectParser       *fakes.ProjectParser
+		detect              packit.DetectFunc
 	)
 
 	it.Before(func() {
 		workingDir = "some-working-dir"
 		buildpackYMLParser = &fakes.VersionParser{}
-		detect = dotnetcoreaspnet.Detect(buildpackYMLParser)
+		runtimeConfigParser = &fakes.ConfigParser{}
+		projectParser = &fakes.ProjectParser{}
+		detect = dotnetcoreaspnet.Detect(buildpackYMLParser, runtimeConfigParser, projectParser)
 	})
 
 	it.After(func() {
@@ -74,7 +79,9 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 					{
 						Name: "dotnet-runtime",
 						Metadata: map[string]interface{}{
-							"build": true,
+							"build":          true,
+							"version":        "1.2.*",
+							"version-source": "buildpack.yml",
 						},
 					},
 					{
@@ -113,7 +120,9 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 					{
 						Name: "dotnet-runtime",
 						Metadata: map[string]interface{}{
-							"build": true,
+							"build":          true,
+							"version":        "1.2.*",
+							"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
 						},
 					},
 					{
@@ -128,6 +137,406 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when the BP_DOTNET_ASPNETCORE_VERSIONS is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_DOTNET_ASPNETCORE_VERSIONS", "6.0.*, 7.0.*,")).To(Succeed())
+			Expect(os.Setenv("BP_DOTNET_FRAMEWORK_VERSION", "6.0.*")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_DOTNET_ASPNETCORE_VERSIONS")).To(Succeed())
+			Expect(os.Unsetenv("BP_DOTNET_FRAMEWORK_VERSION")).To(Succeed())
+		})
+
+		it("requires each version of dotnet-aspnetcore and the runtime of each release", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+				{
+					Name: "dotnet-runtime",
+					Metadata: map[string]interface{}{
+						"build":          true,
+						"version":        "6.0.*",
+						"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+					},
+				},
+				{
+					Name: "dotnet-runtime",
+					Metadata: map[string]interface{}{
+						"build":          true,
+						"version":        "7.0.*",
+						"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+					},
+				},
+				{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+						"version":        "6.0.*",
+					},
+				},
+				{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+						"version":        "7.0.*",
+					},
+				},
+				{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+						"version":        "6.0.*",
+					},
+				},
+			}))
+		})
+	})
+
+	context("when multiple versions are requested and one may roll forward", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_DOTNET_ASPNETCORE_VERSIONS", "6.0.13,7.0.*")).To(Succeed())
+			Expect(os.Setenv("BP_DOTNET_ROLL_FORWARD", "Major")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_DOTNET_ASPNETCORE_VERSIONS")).To(Succeed())
+			Expect(os.Unsetenv("BP_DOTNET_ROLL_FORWARD")).To(Succeed())
+		})
+
+		it("only forwards the releases that are certain to be installed", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+				Name: "dotnet-runtime",
+				Metadata: map[string]interface{}{
+					"build":          true,
+					"version":        "7.0.*",
+					"version-source": "BP_DOTNET_ASPNETCORE_VERSIONS",
+				},
+			}))
+			Expect(result.Plan.Requires[1].Name).To(Equal("dotnet-aspnetcore"))
+		})
+	})
+
+	context("when the requested version does not identify a release", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_DOTNET_FRAMEWORK_VERSION", "*")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_DOTNET_FRAMEWORK_VERSION")).To(Succeed())
+		})
+
+		it("does not forward the version to the dotnet-runtime requirement", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+				Name: "dotnet-runtime",
+				Metadata: map[string]interface{}{
+					"build": true,
+				},
+			}))
+		})
+	})
+
+	context("when the requested version is a major version alias", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_DOTNET_FRAMEWORK_VERSION", "6")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_DOTNET_FRAMEWORK_VERSION")).To(Succeed())
+		})
+
+		it("does not forward the version to the dotnet-runtime requirement", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+				Name: "dotnet-runtime",
+				Metadata: map[string]interface{}{
+					"build": true,
+				},
+			}))
+		})
+	})
+
+	context("when RUNTIME_VERSION is set", func() {
+		it.Before(func() {
+			Expect(os.Setenv("RUNTIME_VERSION", "7.0.1")).To(Succeed())
+			Expect(os.Setenv("BP_DOTNET_FRAMEWORK_VERSION", "6.0.13")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("RUNTIME_VERSION")).To(Succeed())
+			Expect(os.Unsetenv("BP_DOTNET_FRAMEWORK_VERSION")).To(Succeed())
+		})
+
+		it("forwards the release of the RUNTIME_VERSION to the dotnet-runtime requirement", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+				Name: "dotnet-runtime",
+				Metadata: map[string]interface{}{
+					"build":          true,
+					"version":        "7.0.*",
+					"version-source": "RUNTIME_VERSION",
+				},
+			}))
+		})
+
+		context("when BP_DOTNET_IGNORE_RUNTIME_VERSION is set", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_IGNORE_RUNTIME_VERSION", "true")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_IGNORE_RUNTIME_VERSION")).To(Succeed())
+			})
+
+			it("forwards the release of the selected version", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+					Name: "dotnet-runtime",
+					Metadata: map[string]interface{}{
+						"build":          true,
+						"version":        "6.0.*",
+						"version-source": "BP_DOTNET_FRAMEWORK_VERSION",
+					},
+				}))
+			})
+		})
+	})
+
+	context("when the app contains a project file", func() {
+		it.Before(func() {
+			projectParser.FindProjectFileCall.Returns.String = "some-working-dir/some-app.csproj"
+			projectParser.ParseVersionCall.Returns.Version = "6.0.*"
+		})
+
+		it("requires the version of dotnet-aspnetcore targeted by the project", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{
+					{
+						Name: "dotnet-aspnetcore",
+					},
+				},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: "dotnet-runtime",
+						Metadata: map[string]interface{}{
+							"build":          true,
+							"version":        "6.0.*",
+							"version-source": "some-app.csproj",
+						},
+					},
+					{
+						Name: "dotnet-aspnetcore",
+						Metadata: map[string]interface{}{
+							"version-source": "some-app.csproj",
+							"version":        "6.0.*",
+						},
+					},
+				},
+			}))
+
+			Expect(projectParser.FindProjectFileCall.Receives.Root).To(Equal(workingDir))
+			Expect(projectParser.ParseVersionCall.Receives.Path).To(Equal("some-working-dir/some-app.csproj"))
+		})
+
+		context("when the project does not use ASP.NET", func() {
+			it.Before(func() {
+				projectParser.ParseVersionCall.Returns.Version = ""
+			})
+
+			it("does not require a version of dotnet-aspnetcore", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+					{
+						Name: "dotnet-runtime",
+						Metadata: map[string]interface{}{
+							"build": true,
+						},
+					},
+				}))
+			})
+		})
+	})
+
+	context("when the app contains a *.runtimeconfig.json", func() {
+		it.Before(func() {
+			runtimeConfigParser.ParseCall.Returns.RuntimeConfig = dotnetcoreaspnet.RuntimeConfig{
+				Path:          "some-working-dir/some-app.runtimeconfig.json",
+				ASPNETVersion: "6.0.0",
+			}
+		})
+
+		it("requires the version of dotnet-aspnetcore referenced by the app", func() {
+			result, err := detect(packit.DetectContext{
+				WorkingDir: workingDir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plan).To(Equal(packit.BuildPlan{
+				Provides: []packit.BuildPlanProvision{
+					{
+						Name: "dotnet-aspnetcore",
+					},
+				},
+				Requires: []packit.BuildPlanRequirement{
+					{
+						Name: "dotnet-runtime",
+						Metadata: map[string]interface{}{
+							"build": true,
+						},
+					},
+					{
+						Name: "dotnet-aspnetcore",
+						Metadata: map[string]interface{}{
+							"version-source": "runtimeconfig.json",
+							"version":        "6.0.0",
+						},
+					},
+				},
+			}))
+
+			Expect(runtimeConfigParser.ParseCall.Receives.Glob).To(Equal(filepath.Join(workingDir, "*.runtimeconfig.json")))
+		})
+
+		context("when the runtimeconfig.json declares a roll-forward policy", func() {
+			it.Before(func() {
+				runtimeConfigParser.ParseCall.Returns.RuntimeConfig.RollForward = "LatestMinor"
+			})
+
+			it("includes the policy in the requirement", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+					Name: "dotnet-aspnetcore",
+					Metadata: map[string]interface{}{
+						"version-source": "runtimeconfig.json",
+						"version":        "6.0.0",
+						"roll-forward":   "LatestMinor",
+					},
+				}))
+			})
+
+			it("does not forward the version to the dotnet-runtime requirement", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+					Name: "dotnet-runtime",
+					Metadata: map[string]interface{}{
+						"build": true,
+					},
+				}))
+			})
+		})
+
+		context("when the roll-forward policy keeps the version on its release", func() {
+			it.Before(func() {
+				runtimeConfigParser.ParseCall.Returns.RuntimeConfig.RollForward = "LatestPatch"
+			})
+
+			it("forwards the release to the dotnet-runtime requirement", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+					Name: "dotnet-runtime",
+					Metadata: map[string]interface{}{
+						"build":          true,
+						"version":        "6.0.*",
+						"version-source": "runtimeconfig.json",
+					},
+				}))
+			})
+		})
+
+		context("when BP_DOTNET_ROLL_FORWARD rolls the version forward onto the latest major", func() {
+			it.Before(func() {
+				runtimeConfigParser.ParseCall.Returns.RuntimeConfig.RollForward = "LatestPatch"
+				Expect(os.Setenv("BP_DOTNET_ROLL_FORWARD", "LatestMajor")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_ROLL_FORWARD")).To(Succeed())
+			})
+
+			it("does not forward the version to the dotnet-runtime requirement", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires[0]).To(Equal(packit.BuildPlanRequirement{
+					Name: "dotnet-runtime",
+					Metadata: map[string]interface{}{
+						"build": true,
+					},
+				}))
+			})
+		})
+	})
+
+	context("when the app is self-contained", func() {
+		context("when the runtimeconfig.json includes the frameworks", func() {
+			it.Before(func() {
+				runtimeConfigParser.ParseCall.Returns.RuntimeConfig = dotnetcoreaspnet.RuntimeConfig{
+					Path:          "some-working-dir/some-app.runtimeconfig.json",
+					SelfContained: true,
+				}
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(packit.Fail.WithMessage("application is self-contained: it provides its own ASP.NET Core framework")))
+			})
+		})
+
+		context("when the app includes the host", func() {
+			it.Before(func() {
+				var err error
+				workingDir, err = os.MkdirTemp("", "working-dir")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.WriteFile(filepath.Join(workingDir, "libhostfxr.so"), nil, 0600)).To(Succeed())
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(packit.Fail.WithMessage("application is self-contained: it provides its own ASP.NET Core framework")))
+			})
+		})
+	})
+
 	context("failure cases", func() {
 		context("when the buildpack.yml parser fails", func() {
 			it.Before(func() {
@@ -141,5 +550,62 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				Expect(err).To(MatchError("failed to parse buildpack.yml"))
 			})
 		})
+
+		context("when the project file cannot be found", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.Error = errors.New("failed to find project file")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to find project file"))
+			})
+		})
+
+		context("when the project file parser fails", func() {
+			it.Before(func() {
+				projectParser.FindProjectFileCall.Returns.String = "/working-dir/some-app.csproj"
+				projectParser.ParseVersionCall.Returns.Err = errors.New("failed to parse project file")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse project file"))
+			})
+		})
+
+		context("when BP_DOTNET_IGNORE_RUNTIME_VERSION is not a boolean", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_DOTNET_IGNORE_RUNTIME_VERSION", "some-value")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_DOTNET_IGNORE_RUNTIME_VERSION")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(`invalid value for BP_DOTNET_IGNORE_RUNTIME_VERSION "some-value": must be a boolean`))
+			})
+		})
+
+		context("when the runtimeconfig.json parser fails", func() {
+			it.Before(func() {
+				runtimeConfigParser.ParseCall.Returns.Error = errors.New("failed to parse runtimeconfig.json")
+			})
+
+			it("returns an error", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: "/working-dir",
+				})
+				Expect(err).To(MatchError("failed to parse runtimeconfig.json"))
+			})
+		})
 	})
 }
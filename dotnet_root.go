@@ -0,0 +1,103 @@
+package dotnetcoreaspnet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// dotnetRoots returns the locations in which the Microsoft.NETCore.App
+// runtime may have been installed by an earlier buildpack.
+func dotnetRoots(workingDir string) []string {
+	roots := []string{filepath.Join(workingDir, ".dotnet_root")}
+	if root, ok := os.LookupEnv("DOTNET_ROOT"); ok && root != roots[0] {
+		roots = append(roots, root)
+	}
+
+	return roots
+}
+
+// dotnetRootStrategy returns the strategy configured through
+// BP_DOTNET_ROOT_STRATEGY for hosting the dotnet root. By default the dotnet
+// root is created in the working directory; the "layer" strategy hosts it in
+// a dedicated layer instead, leaving the working directory untouched.
+func dotnetRootStrategy() (string, error) {
+	strategy, ok := os.LookupEnv("BP_DOTNET_ROOT_STRATEGY")
+	if !ok || strategy == "" {
+		return "working-dir", nil
+	}
+
+	if strategy != "working-dir" && strategy != "layer" {
+		return "", fmt.Errorf("unsupported dotnet root strategy %q: must be one of %q or %q", strategy, "working-dir", "layer")
+	}
+
+	return strategy, nil
+}
+
+// linkDotnetRoot links the frameworks installed at layerPath into the dotnet
+// root selected by the given strategy and returns its path. With the "layer"
+// strategy, the dotnet root is rebuilt on every build in a dedicated layer,
+// which is returned alongside its path, from the dotnet roots provided by
+// earlier buildpacks and the frameworks installed at layerPath. The links of
+// those dotnet roots are resolved, so that the layer points at the layers
+// that provide the frameworks rather than through the working directory.
+func linkDotnetRoot(context packit.BuildContext, symlinker Symlinker, strategy, layerPath string, launch, build bool) (string, []packit.Layer, error) {
+	if strategy != "layer" {
+		dotnetRoot := filepath.Join(context.WorkingDir, ".dotnet_root")
+		return dotnetRoot, nil, symlinker.Link(dotnetRoot, layerPath)
+	}
+
+	rootLayer, err := context.Layers.Get("dotnet-root")
+	if err != nil {
+		return "", nil, err
+	}
+
+	rootLayer, err = rootLayer.Reset()
+	if err != nil {
+		return "", nil, err
+	}
+
+	rootLayer.Launch, rootLayer.Build = launch, build
+
+	for _, root := range dotnetRoots(context.WorkingDir) {
+		_, err := os.Stat(root)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return "", nil, err
+		}
+
+		err = symlinker.Link(rootLayer.Path, root)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	err = symlinker.Link(rootLayer.Path, layerPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	setDotnetRoot(&rootLayer, rootLayer.Path, launch, build)
+
+	return rootLayer.Path, []packit.Layer{rootLayer}, nil
+}
+
+// setDotnetRoot exports DOTNET_ROOT from the given layer to the phases in
+// which the layer is made available, so that downstream buildpacks requiring
+// the framework at build time can find it as well.
+func setDotnetRoot(layer *packit.Layer, dotnetRoot string, launch, build bool) {
+	switch {
+	case launch && build:
+		layer.SharedEnv.Override("DOTNET_ROOT", dotnetRoot)
+	case build:
+		layer.BuildEnv.Override("DOTNET_ROOT", dotnetRoot)
+	case launch:
+		layer.LaunchEnv.Override("DOTNET_ROOT", dotnetRoot)
+	}
+}
@@ -1,30 +1,319 @@
 package dotnetcoreaspnet
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// LinkMode controls the level at which frameworks are linked into the dotnet
+// root.
+type LinkMode string
+
+const (
+	// FrameworkLinkMode links each framework directory, e.g.
+	// shared/Microsoft.AspNetCore.App, as a whole.
+	FrameworkLinkMode LinkMode = "framework"
+
+	// VersionLinkMode creates each framework directory and links its version
+	// directories individually, so that several layers can contribute
+	// versions of the same framework.
+	VersionLinkMode LinkMode = "version"
 )
 
-type DotnetRootLinker struct{}
+type DotnetRootLinker struct {
+	logger scribe.Emitter
+	mode   LinkMode
+}
 
-func NewDotnetRootLinker() DotnetRootLinker {
-	return DotnetRootLinker{}
+func NewDotnetRootLinker(logger scribe.Emitter) DotnetRootLinker {
+	return DotnetRootLinker{
+		logger: logger,
+		mode:   FrameworkLinkMode,
+	}
 }
 
-func (dl DotnetRootLinker) Link(workingDir, layerPath string) error {
-	err := os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared"), os.ModePerm)
+func (dl DotnetRootLinker) WithMode(mode LinkMode) DotnetRootLinker {
+	if mode != "" {
+		dl.mode = mode
+	}
+
+	return dl
+}
+
+// Link links the frameworks and host components installed at layerPath into
+// the dotnet root directory.
+func (dl DotnetRootLinker) Link(dotnetRoot, layerPath string) error {
+	if dl.mode != FrameworkLinkMode && dl.mode != VersionLinkMode {
+		return fmt.Errorf("unsupported link mode %q: must be one of %q or %q", dl.mode, FrameworkLinkMode, VersionLinkMode)
+	}
+
+	err := os.MkdirAll(filepath.Join(dotnetRoot, "shared"), os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	files, err := filepath.Glob(filepath.Join(layerPath, "shared", "*"))
+	// both shared/<framework>/<version> and host/fxr/<version> share the same
+	// layout, so they can be linked the same way
+	for _, dir := range []string{"shared", "host"} {
+		files, err := filepath.Glob(filepath.Join(layerPath, dir, "*"))
+		if err != nil {
+			return err
+		}
+
+		if len(files) == 0 {
+			continue
+		}
+
+		err = os.MkdirAll(filepath.Join(dotnetRoot, dir), os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			filename := filepath.Base(f)
+
+			// the frameworks of a dotnet root may themselves be links into the
+			// layers that provide them, which are linked to directly so that
+			// the links do not depend on the location of that dotnet root
+			source, err := filepath.EvalSymlinks(filepath.Join(layerPath, dir, filename))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					dl.logger.Debug.Subprocess("Skipping %s: link target does not exist", f)
+					continue
+				}
+
+				return err
+			}
+
+			err = dl.linkFramework(source, filepath.Join(dotnetRoot, dir, filename))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// the dotnet muxer is provided by the runtime, link it when it is present
+	// so that the dotnet root is usable on its own
+	muxer, err := filepath.EvalSymlinks(filepath.Join(layerPath, "dotnet"))
+	if err == nil {
+		_, err = os.Lstat(filepath.Join(dotnetRoot, "dotnet"))
+		if errors.Is(err, os.ErrNotExist) {
+			return os.Symlink(muxer, filepath.Join(dotnetRoot, "dotnet"))
+		}
+	}
+
+	return nil
+}
+
+// linkFramework links the framework directory at source into the dotnet root
+// at target. When the target is already provided by another buildpack, the
+// versions of the framework are merged into a single directory instead.
+func (dl DotnetRootLinker) linkFramework(source, target string) error {
+	linkFarm, err := isLinkFarm(source)
 	if err != nil {
 		return err
 	}
 
-	for _, f := range files {
-		filename := filepath.Base(f)
-		err := os.Symlink(filepath.Join(layerPath, "shared", filename), filepath.Join(workingDir, ".dotnet_root", "shared", filename))
+	info, err := os.Lstat(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// a framework directory whose versions are links, such as one
+			// created in version mode, is linked version by version so that
+			// the links point at the layers that provide the versions
+			if dl.mode == VersionLinkMode || linkFarm {
+				err = os.Mkdir(target, os.ModePerm)
+				if err != nil {
+					return err
+				}
+
+				return dl.linkVersions(source, target)
+			}
+
+			dl.logger.Debug.Subprocess("Linking %s to %s", target, source)
+			return os.Symlink(source, target)
+		}
+
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		destination, err := readLink(target)
+		if err != nil {
+			return err
+		}
+
+		if destination == source {
+			if dl.mode == FrameworkLinkMode {
+				dl.logger.Debug.Subprocess("%s is already linked to %s", target, source)
+				return nil
+			}
+
+			dl.logger.Subprocess("Replacing framework link %s with version links", target)
+			err = os.Remove(target)
+			if err != nil {
+				return err
+			}
+
+			err = os.Mkdir(target, os.ModePerm)
+			if err != nil {
+				return err
+			}
+
+			return dl.linkVersions(source, target)
+		}
+
+		_, err = os.Stat(target)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			dl.logger.Subprocess("Replacing stale link %s -> %s", target, destination)
+			err = os.Remove(target)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(source, target)
+		}
+
+		// the framework directory is owned by another buildpack, so it is
+		// replaced with a directory that links the versions of both
+		dl.logger.Subprocess("Merging %s with the versions provided by %s", target, destination)
+		err = os.Remove(target)
+		if err != nil {
+			return err
+		}
+
+		err = os.Mkdir(target, os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		err = dl.linkVersions(destination, target)
+		if err != nil {
+			return err
+		}
+
+		return dl.linkVersions(source, target)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("failed to link %s: file exists and is not a directory", target)
+	}
+
+	if dl.mode == FrameworkLinkMode {
+		dl.logger.Subprocess("Merging versions into existing directory %s", target)
+	}
+
+	return dl.linkVersions(source, target)
+}
+
+// linkVersions links each version directory of the framework at source into
+// the directory at target. Versions that are already provided are left in
+// place, while links to versions that are no longer installed at source are
+// removed.
+func (dl DotnetRootLinker) linkVersions(source, target string) error {
+	versions, err := os.ReadDir(source)
+	if err != nil {
+		return err
+	}
+
+	err = dl.removeStaleVersions(source, target)
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		versionSource, err := filepath.EvalSymlinks(filepath.Join(source, version.Name()))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return err
+		}
+
+		versionTarget := filepath.Join(target, version.Name())
+
+		_, err = os.Lstat(versionTarget)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		if err == nil {
+			destination, _ := readLink(versionTarget)
+			if destination == versionSource {
+				continue
+			}
+
+			_, err = os.Stat(versionTarget)
+			if err == nil {
+				dl.logger.Subprocess("Skipping %s: version is already provided", versionTarget)
+				continue
+			}
+
+			if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			dl.logger.Subprocess("Replacing stale link %s -> %s", versionTarget, destination)
+			err = os.Remove(versionTarget)
+			if err != nil {
+				return err
+			}
+		}
+
+		dl.logger.Debug.Subprocess("Linking %s to %s", versionTarget, versionSource)
+		err = os.Symlink(versionSource, versionTarget)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeStaleVersions removes the links in the directory at target that point
+// at versions of the framework at source that no longer exist, such as when
+// a patch of the framework replaced the version that was linked by a
+// previous build.
+func (dl DotnetRootLinker) removeStaleVersions(source, target string) error {
+	links, err := os.ReadDir(target)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if link.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		path := filepath.Join(target, link.Name())
+		destination, err := readLink(path)
+		if err != nil {
+			return err
+		}
+
+		if filepath.Dir(destination) != filepath.Clean(source) {
+			continue
+		}
+
+		_, err = os.Stat(path)
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		dl.logger.Subprocess("Removing stale link %s -> %s", path, destination)
+		err = os.Remove(path)
 		if err != nil {
 			return err
 		}
@@ -32,3 +321,49 @@ func (dl DotnetRootLinker) Link(workingDir, layerPath string) error {
 
 	return nil
 }
+
+// readLink returns the destination of the symbolic link at path. Relative
+// destinations are resolved against the directory that contains the link.
+func readLink(path string) (string, error) {
+	destination, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(destination) {
+		destination = filepath.Join(filepath.Dir(path), destination)
+	}
+
+	return destination, nil
+}
+
+// isLinkFarm returns whether the framework directory at path links its
+// versions from elsewhere instead of containing them.
+func isLinkFarm(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return false, err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink != 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func linkMode() string {
+	mode := os.Getenv("BP_DOTNET_ROOT_LINK_MODE")
+	if mode == "" {
+		return string(FrameworkLinkMode)
+	}
+
+	return mode
+}
@@ -1,11 +1,13 @@
 package dotnetcoreaspnet_test
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
 
 	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -18,6 +20,7 @@ func testDotnetRootLinker(t *testing.T, context spec.G, it spec.S) {
 		dotnetLinker dotnetcoreaspnet.DotnetRootLinker
 		workingDir   string
 		layerPath    string
+		buffer       *bytes.Buffer
 	)
 
 	it.Before(func() {
@@ -28,10 +31,11 @@ func testDotnetRootLinker(t *testing.T, context spec.G, it spec.S) {
 		layerPath, err = os.MkdirTemp("", "layer-path")
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "dir1"), os.ModePerm)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "dir1", "6.0.13"), os.ModePerm)).To(Succeed())
 		Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "dir2"), os.ModePerm)).To(Succeed())
 
-		dotnetLinker = dotnetcoreaspnet.NewDotnetRootLinker()
+		buffer = bytes.NewBuffer(nil)
+		dotnetLinker = dotnetcoreaspnet.NewDotnetRootLinker(scribe.NewEmitter(buffer))
 	})
 
 	it.After(func() {
@@ -41,7 +45,7 @@ func testDotnetRootLinker(t *testing.T, context spec.G, it spec.S) {
 
 	context("Link", func() {
 		it("creates a .dotnet_root dir in workspace with symlink to layerpath", func() {
-			err := dotnetLinker.Link(workingDir, layerPath)
+			err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(filepath.Join(workingDir, ".dotnet_root")).To(BeADirectory())
 
@@ -62,13 +66,279 @@ func testDotnetRootLinker(t *testing.T, context spec.G, it spec.S) {
 			Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir2")))
 		})
 
+		context("when the layer provides host components", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(layerPath, "host", "fxr", "6.0.13"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layerPath, "dotnet"), nil, 0755)).To(Succeed())
+			})
+
+			it("links them into the dotnet root", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "host", "fxr"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "host", "fxr")))
+
+				link, err = os.Readlink(filepath.Join(workingDir, ".dotnet_root", "dotnet"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "dotnet")))
+			})
+		})
+
+		context("when the frameworks are links into other layers", func() {
+			var runtimeLayerPath, rootLayerPath string
+
+			it.Before(func() {
+				var err error
+				runtimeLayerPath, err = os.MkdirTemp("", "runtime-layer-path")
+				Expect(err).NotTo(HaveOccurred())
+
+				rootLayerPath, err = os.MkdirTemp("", "root-layer-path")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.MkdirAll(filepath.Join(runtimeLayerPath, "shared", "Microsoft.NETCore.App", "6.0.13"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(runtimeLayerPath, "shared", "Microsoft.WindowsDesktop.App", "6.0.13"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(runtimeLayerPath, "dotnet"), nil, 0755)).To(Succeed())
+
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared", "Microsoft.WindowsDesktop.App"), os.ModePerm)).To(Succeed())
+				Expect(os.Symlink(filepath.Join(runtimeLayerPath, "shared", "Microsoft.NETCore.App"), filepath.Join(workingDir, ".dotnet_root", "shared", "Microsoft.NETCore.App"))).To(Succeed())
+				Expect(os.Symlink(filepath.Join(runtimeLayerPath, "shared", "Microsoft.WindowsDesktop.App", "6.0.13"), filepath.Join(workingDir, ".dotnet_root", "shared", "Microsoft.WindowsDesktop.App", "6.0.13"))).To(Succeed())
+				Expect(os.Symlink(filepath.Join(runtimeLayerPath, "dotnet"), filepath.Join(workingDir, ".dotnet_root", "dotnet"))).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(runtimeLayerPath)).To(Succeed())
+				Expect(os.RemoveAll(rootLayerPath)).To(Succeed())
+			})
+
+			it("links to the layers directly", func() {
+				err := dotnetLinker.Link(rootLayerPath, filepath.Join(workingDir, ".dotnet_root"))
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(rootLayerPath, "shared", "Microsoft.NETCore.App"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(runtimeLayerPath, "shared", "Microsoft.NETCore.App")))
+
+				fi, err := os.Lstat(filepath.Join(rootLayerPath, "shared", "Microsoft.WindowsDesktop.App"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.IsDir()).To(BeTrue())
+
+				link, err = os.Readlink(filepath.Join(rootLayerPath, "shared", "Microsoft.WindowsDesktop.App", "6.0.13"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(runtimeLayerPath, "shared", "Microsoft.WindowsDesktop.App", "6.0.13")))
+
+				link, err = os.Readlink(filepath.Join(rootLayerPath, "dotnet"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(runtimeLayerPath, "dotnet")))
+			})
+		})
+
+		context("when the links already exist", func() {
+			it.Before(func() {
+				Expect(dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)).To(Succeed())
+			})
+
+			it("leaves them in place", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1")))
+
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+
+		context("when a link points at a path that no longer exists", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared"), os.ModePerm)).To(Succeed())
+				Expect(os.Symlink("/some/old/layer/shared/dir1", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))).To(Succeed())
+			})
+
+			it("replaces the stale link", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1")))
+
+				Expect(buffer.String()).To(ContainSubstring("Replacing stale link %s -> /some/old/layer/shared/dir1", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1")))
+			})
+		})
+
+		context("when another buildpack has linked the framework", func() {
+			var otherLayerPath string
+
+			it.Before(func() {
+				var err error
+				otherLayerPath, err = os.MkdirTemp("", "other-layer-path")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(os.MkdirAll(filepath.Join(otherLayerPath, "shared", "dir1", "7.0.2"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared"), os.ModePerm)).To(Succeed())
+				Expect(os.Symlink(filepath.Join(otherLayerPath, "shared", "dir1"), filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(otherLayerPath)).To(Succeed())
+			})
+
+			it("merges the versions of both into a directory", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				fi, err := os.Lstat(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.IsDir()).To(BeTrue())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "7.0.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(otherLayerPath, "shared", "dir1", "7.0.2")))
+
+				link, err = os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1", "6.0.13")))
+
+				Expect(buffer.String()).To(ContainSubstring("Merging %s with the versions provided by %s", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"), filepath.Join(otherLayerPath, "shared", "dir1")))
+			})
+		})
+
+		context("when another buildpack has linked the framework through a relative path", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "other-layer", "shared", "dir1", "7.0.2"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared"), os.ModePerm)).To(Succeed())
+				Expect(os.Symlink(filepath.Join("..", "..", "other-layer", "shared", "dir1"), filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))).To(Succeed())
+			})
+
+			it("resolves the link against its directory", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "7.0.2"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(workingDir, "other-layer", "shared", "dir1", "7.0.2")))
+				Expect(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "7.0.2")).To(BeADirectory())
+
+				link, err = os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1", "6.0.13")))
+			})
+		})
+
+		context("when the framework is already a directory", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "7.0.2"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "dir1", "6.0.14"), os.ModePerm)).To(Succeed())
+				Expect(os.Symlink("/some/old/layer/shared/dir1/6.0.14", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.14"))).To(Succeed())
+			})
+
+			it("links the versions that are not already provided", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "7.0.2")).To(BeADirectory())
+
+				fi, err := os.Lstat(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.Mode() & os.ModeSymlink).To(BeZero())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.14"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1", "6.0.14")))
+
+				Expect(buffer.String()).To(ContainSubstring("Merging versions into existing directory %s", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1")))
+				Expect(buffer.String()).To(ContainSubstring("Skipping %s: version is already provided", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13")))
+				Expect(buffer.String()).To(ContainSubstring("Replacing stale link %s -> /some/old/layer/shared/dir1/6.0.14", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.14")))
+			})
+		})
+
+		context("when linking in version mode", func() {
+			it.Before(func() {
+				dotnetLinker = dotnetLinker.WithMode(dotnetcoreaspnet.VersionLinkMode)
+			})
+
+			it("creates the framework directories and links each version", func() {
+				err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				fi, err := os.Lstat(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fi.IsDir()).To(BeTrue())
+
+				link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1", "6.0.13")))
+
+				Expect(filepath.Join(workingDir, ".dotnet_root", "shared", "dir2")).To(BeADirectory())
+			})
+
+			context("when the framework was previously linked as a whole", func() {
+				it.Before(func() {
+					Expect(dotnetcoreaspnet.NewDotnetRootLinker(scribe.NewEmitter(bytes.NewBuffer(nil))).Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)).To(Succeed())
+				})
+
+				it("replaces the framework link with version links", func() {
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+					Expect(err).NotTo(HaveOccurred())
+
+					fi, err := os.Lstat(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fi.IsDir()).To(BeTrue())
+
+					link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1", "6.0.13")))
+
+					Expect(buffer.String()).To(ContainSubstring("Replacing framework link %s with version links", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1")))
+				})
+			})
+
+			context("when the versions are already linked", func() {
+				it.Before(func() {
+					Expect(dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)).To(Succeed())
+				})
+
+				it("leaves them in place", func() {
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(buffer.String()).To(BeEmpty())
+				})
+			})
+			context("when a linked version is no longer installed", func() {
+				it.Before(func() {
+					Expect(dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)).To(Succeed())
+
+					Expect(os.RemoveAll(filepath.Join(layerPath, "shared", "dir1", "6.0.13"))).To(Succeed())
+					Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "dir1", "6.0.14"), os.ModePerm)).To(Succeed())
+				})
+
+				it("removes the link to that version", func() {
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = os.Lstat(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"))
+					Expect(err).To(MatchError(os.ErrNotExist))
+
+					link, err := os.Readlink(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.14"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(link).To(Equal(filepath.Join(layerPath, "shared", "dir1", "6.0.14")))
+
+					Expect(buffer.String()).To(ContainSubstring("Removing stale link %s -> %s", filepath.Join(workingDir, ".dotnet_root", "shared", "dir1", "6.0.13"), filepath.Join(layerPath, "shared", "dir1", "6.0.13")))
+				})
+			})
+		})
+
 		context("error cases", func() {
 			context("when the '.dotnet_root' dir can not be created", func() {
 				it.Before(func() {
 					Expect(os.Chmod(filepath.Join(workingDir), 0000)).To(Succeed())
 				})
 				it("errors", func() {
-					err := dotnetLinker.Link(workingDir, layerPath)
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
@@ -82,15 +352,42 @@ func testDotnetRootLinker(t *testing.T, context spec.G, it spec.S) {
 				})
 			})
 
-			context("when the symlink can not be created", func() {
+			context("when the link mode is not supported", func() {
+				it.Before(func() {
+					dotnetLinker = dotnetLinker.WithMode("some-mode")
+				})
+
+				it("returns an error", func() {
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+					Expect(err).To(MatchError(`unsupported link mode "some-mode": must be one of "framework" or "version"`))
+				})
+			})
+
+			context("when the framework path is occupied by a file", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared"), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"), nil, 0600)).To(Succeed())
+				})
+
+				it("errors", func() {
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+					Expect(err).To(MatchError(ContainSubstring("file exists and is not a directory")))
+				})
+			})
+
+			context("when the existing framework directory can not be written", func() {
 				it.Before(func() {
 					Expect(os.MkdirAll(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"), os.ModePerm)).To(Succeed())
+					Expect(os.Chmod(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"), 0500)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(filepath.Join(workingDir, ".dotnet_root", "shared", "dir1"), os.ModePerm)).To(Succeed())
 				})
 
 				it("errors", func() {
-					err := dotnetLinker.Link(workingDir, layerPath)
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(ContainSubstring("file exists")))
+					err := dotnetLinker.Link(filepath.Join(workingDir, ".dotnet_root"), layerPath)
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
 				})
 			})
 		})
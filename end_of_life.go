@@ -0,0 +1,54 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// checkEndOfLife warns about every dependency that reaches the end of support
+// within the window configured through BP_DOTNET_EOL_WARNING_DAYS (90 days by
+// default). Dependencies that are already out of support are reported when
+// they are selected, and fail the build when BP_DOTNET_FAIL_ON_EOL is true.
+func checkEndOfLife(deps []postal.Dependency, now time.Time, logger scribe.Emitter) error {
+	days := 90
+	if value, ok := os.LookupEnv("BP_DOTNET_EOL_WARNING_DAYS"); ok && value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return fmt.Errorf("invalid value for BP_DOTNET_EOL_WARNING_DAYS %q: must be a non-negative number of days", value)
+		}
+	}
+
+	failOnEOL, err := boolEnv("BP_DOTNET_FAIL_ON_EOL")
+	if err != nil {
+		return err
+	}
+
+	var expired []string
+	for _, dependency := range deps {
+		if dependency.DeprecationDate.IsZero() {
+			continue
+		}
+
+		switch {
+		case !dependency.DeprecationDate.After(now):
+			expired = append(expired, dependency.Version)
+		case dependency.DeprecationDate.Before(now.AddDate(0, 0, days)):
+			logger.Subprocess("WARNING: %s %s reaches its end of support on %s.", dependency.Name, dependency.Version, dependency.DeprecationDate.Format("2006-01-02"))
+			logger.Subprocess("Migrate your application to a supported version of %s.", dependency.Name)
+			logger.Break()
+		}
+	}
+
+	if failOnEOL && len(expired) > 0 {
+		return fmt.Errorf("failed to satisfy support policy: .NET Core ASPNet %s is out of support and BP_DOTNET_FAIL_ON_EOL is set", strings.Join(expired, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// boolEnv returns the boolean value of the given environment variable, which
+// is false when the variable is not set.
+func boolEnv(name string) (bool, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s %q: must be a boolean", name, value)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,33 @@
+package fakes
+
+import (
+	"sync"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+)
+
+type AppAnalyzer struct {
+	AnalyzeCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			WorkingDir string
+		}
+		Returns struct {
+			AppAnalysis dotnetcoreaspnet.AppAnalysis
+			Error       error
+		}
+		Stub func(string) (dotnetcoreaspnet.AppAnalysis, error)
+	}
+}
+
+func (f *AppAnalyzer) Analyze(param1 string) (dotnetcoreaspnet.AppAnalysis, error) {
+	f.AnalyzeCall.mutex.Lock()
+	defer f.AnalyzeCall.mutex.Unlock()
+	f.AnalyzeCall.CallCount++
+	f.AnalyzeCall.Receives.WorkingDir = param1
+	if f.AnalyzeCall.Stub != nil {
+		return f.AnalyzeCall.Stub(param1)
+	}
+	return f.AnalyzeCall.Returns.AppAnalysis, f.AnalyzeCall.Returns.Error
+}
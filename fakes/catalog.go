@@ -0,0 +1,59 @@
+package fakes
+
+import (
+	"sync"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+)
+
+type Catalog struct {
+	DefaultVersionCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Path string
+			Id   string
+		}
+		Returns struct {
+			String string
+			Error  error
+		}
+		Stub func(string, string) (string, error)
+	}
+	DependenciesCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Path string
+			Id   string
+		}
+		Returns struct {
+			CatalogDependencySlice []dotnetcoreaspnet.CatalogDependency
+			Error                  error
+		}
+		Stub func(string, string) ([]dotnetcoreaspnet.CatalogDependency, error)
+	}
+}
+
+func (f *Catalog) DefaultVersion(param1 string, param2 string) (string, error) {
+	f.DefaultVersionCall.mutex.Lock()
+	defer f.DefaultVersionCall.mutex.Unlock()
+	f.DefaultVersionCall.CallCount++
+	f.DefaultVersionCall.Receives.Path = param1
+	f.DefaultVersionCall.Receives.Id = param2
+	if f.DefaultVersionCall.Stub != nil {
+		return f.DefaultVersionCall.Stub(param1, param2)
+	}
+	return f.DefaultVersionCall.Returns.String, f.DefaultVersionCall.Returns.Error
+}
+func (f *Catalog) Dependencies(param1 string, param2 string) ([]dotnetcoreaspnet.CatalogDependency, error) {
+	f.DependenciesCall.mutex.Lock()
+	defer f.DependenciesCall.mutex.Unlock()
+	f.DependenciesCall.CallCount++
+	f.DependenciesCall.Receives.Path = param1
+	f.DependenciesCall.Receives.Id = param2
+	if f.DependenciesCall.Stub != nil {
+		return f.DependenciesCall.Stub(param1, param2)
+	}
+	return f.DependenciesCall.Returns.CatalogDependencySlice, f.DependenciesCall.Returns.Error
+}
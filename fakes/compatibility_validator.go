@@ -0,0 +1,30 @@
+package fakes
+
+import "sync"
+
+type CompatibilityValidator struct {
+	ValidateCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			LayerPath   string
+			DotnetRoots []string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(string, []string) error
+	}
+}
+
+func (f *CompatibilityValidator) Validate(param1 string, param2 []string) error {
+	f.ValidateCall.mutex.Lock()
+	defer f.ValidateCall.mutex.Unlock()
+	f.ValidateCall.CallCount++
+	f.ValidateCall.Receives.LayerPath = param1
+	f.ValidateCall.Receives.DotnetRoots = param2
+	if f.ValidateCall.Stub != nil {
+		return f.ValidateCall.Stub(param1, param2)
+	}
+	return f.ValidateCall.Returns.Error
+}
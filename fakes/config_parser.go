@@ -0,0 +1,33 @@
+package fakes
+
+import (
+	"sync"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+)
+
+type ConfigParser struct {
+	ParseCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Glob string
+		}
+		Returns struct {
+			RuntimeConfig dotnetcoreaspnet.RuntimeConfig
+			Error         error
+		}
+		Stub func(string) (dotnetcoreaspnet.RuntimeConfig, error)
+	}
+}
+
+func (f *ConfigParser) Parse(param1 string) (dotnetcoreaspnet.RuntimeConfig, error) {
+	f.ParseCall.mutex.Lock()
+	defer f.ParseCall.mutex.Unlock()
+	f.ParseCall.CallCount++
+	f.ParseCall.Receives.Glob = param1
+	if f.ParseCall.Stub != nil {
+		return f.ParseCall.Stub(param1)
+	}
+	return f.ParseCall.Returns.RuntimeConfig, f.ParseCall.Returns.Error
+}
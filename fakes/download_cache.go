@@ -0,0 +1,62 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+type DownloadCache struct {
+	FetchCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Dependency   postal.Dependency
+			CnbPath      string
+			PlatformPath string
+			CacheDir     string
+		}
+		Returns struct {
+			Dependency postal.Dependency
+			Error      error
+		}
+		Stub func(postal.Dependency, string, string, string) (postal.Dependency, error)
+	}
+	PruneCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			CacheDir string
+			Keep     []postal.Dependency
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(string, []postal.Dependency) error
+	}
+}
+
+func (f *DownloadCache) Fetch(param1 postal.Dependency, param2 string, param3 string, param4 string) (postal.Dependency, error) {
+	f.FetchCall.mutex.Lock()
+	defer f.FetchCall.mutex.Unlock()
+	f.FetchCall.CallCount++
+	f.FetchCall.Receives.Dependency = param1
+	f.FetchCall.Receives.CnbPath = param2
+	f.FetchCall.Receives.PlatformPath = param3
+	f.FetchCall.Receives.CacheDir = param4
+	if f.FetchCall.Stub != nil {
+		return f.FetchCall.Stub(param1, param2, param3, param4)
+	}
+	return f.FetchCall.Returns.Dependency, f.FetchCall.Returns.Error
+}
+func (f *DownloadCache) Prune(param1 string, param2 []postal.Dependency) error {
+	f.PruneCall.mutex.Lock()
+	defer f.PruneCall.mutex.Unlock()
+	f.PruneCall.CallCount++
+	f.PruneCall.Receives.CacheDir = param1
+	f.PruneCall.Receives.Keep = param2
+	if f.PruneCall.Stub != nil {
+		return f.PruneCall.Stub(param1, param2)
+	}
+	return f.PruneCall.Returns.Error
+}
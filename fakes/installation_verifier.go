@@ -0,0 +1,30 @@
+package fakes
+
+import "sync"
+
+type InstallationVerifier struct {
+	VerifyCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			LayerPath string
+			Version   string
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(string, string) error
+	}
+}
+
+func (f *InstallationVerifier) Verify(param1 string, param2 string) error {
+	f.VerifyCall.mutex.Lock()
+	defer f.VerifyCall.mutex.Unlock()
+	f.VerifyCall.CallCount++
+	f.VerifyCall.Receives.LayerPath = param1
+	f.VerifyCall.Receives.Version = param2
+	if f.VerifyCall.Stub != nil {
+		return f.VerifyCall.Stub(param1, param2)
+	}
+	return f.VerifyCall.Returns.Error
+}
@@ -0,0 +1,51 @@
+package fakes
+
+import "sync"
+
+type ProjectParser struct {
+	FindProjectFileCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Root string
+		}
+		Returns struct {
+			String string
+			Error  error
+		}
+		Stub func(string) (string, error)
+	}
+	ParseVersionCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Path string
+		}
+		Returns struct {
+			Version string
+			Err     error
+		}
+		Stub func(string) (string, error)
+	}
+}
+
+func (f *ProjectParser) FindProjectFile(param1 string) (string, error) {
+	f.FindProjectFileCall.mutex.Lock()
+	defer f.FindProjectFileCall.mutex.Unlock()
+	f.FindProjectFileCall.CallCount++
+	f.FindProjectFileCall.Receives.Root = param1
+	if f.FindProjectFileCall.Stub != nil {
+		return f.FindProjectFileCall.Stub(param1)
+	}
+	return f.FindProjectFileCall.Returns.String, f.FindProjectFileCall.Returns.Error
+}
+func (f *ProjectParser) ParseVersion(param1 string) (string, error) {
+	f.ParseVersionCall.mutex.Lock()
+	defer f.ParseVersionCall.mutex.Unlock()
+	f.ParseVersionCall.CallCount++
+	f.ParseVersionCall.Receives.Path = param1
+	if f.ParseVersionCall.Stub != nil {
+		return f.ParseVersionCall.Stub(param1)
+	}
+	return f.ParseVersionCall.Returns.Version, f.ParseVersionCall.Returns.Err
+}
@@ -8,29 +8,29 @@ import (
 )
 
 type SBOMGenerator struct {
-	GenerateFromDependencyCall struct {
+	GenerateFromDependenciesCall struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
-			Dependency postal.Dependency
-			Dir        string
+			Dependencies []postal.Dependency
+			Dir          string
 		}
 		Returns struct {
 			SBOM  sbom.SBOM
 			Error error
 		}
-		Stub func(postal.Dependency, string) (sbom.SBOM, error)
+		Stub func([]postal.Dependency, string) (sbom.SBOM, error)
 	}
 }
 
-func (f *SBOMGenerator) GenerateFromDependency(param1 postal.Dependency, param2 string) (sbom.SBOM, error) {
-	f.GenerateFromDependencyCall.mutex.Lock()
-	defer f.GenerateFromDependencyCall.mutex.Unlock()
-	f.GenerateFromDependencyCall.CallCount++
-	f.GenerateFromDependencyCall.Receives.Dependency = param1
-	f.GenerateFromDependencyCall.Receives.Dir = param2
-	if f.GenerateFromDependencyCall.Stub != nil {
-		return f.GenerateFromDependencyCall.Stub(param1, param2)
+func (f *SBOMGenerator) GenerateFromDependencies(param1 []postal.Dependency, param2 string) (sbom.SBOM, error) {
+	f.GenerateFromDependenciesCall.mutex.Lock()
+	defer f.GenerateFromDependenciesCall.mutex.Unlock()
+	f.GenerateFromDependenciesCall.CallCount++
+	f.GenerateFromDependenciesCall.Receives.Dependencies = param1
+	f.GenerateFromDependenciesCall.Receives.Dir = param2
+	if f.GenerateFromDependenciesCall.Stub != nil {
+		return f.GenerateFromDependenciesCall.Stub(param1, param2)
 	}
-	return f.GenerateFromDependencyCall.Returns.SBOM, f.GenerateFromDependencyCall.Returns.Error
+	return f.GenerateFromDependenciesCall.Returns.SBOM, f.GenerateFromDependenciesCall.Returns.Error
 }
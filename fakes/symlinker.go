@@ -7,7 +7,7 @@ type Symlinker struct {
 		mutex     sync.Mutex
 		CallCount int
 		Receives  struct {
-			WorkingDir string
+			DotnetRoot string
 			LayerPath  string
 		}
 		Returns struct {
@@ -21,7 +21,7 @@ func (f *Symlinker) Link(param1 string, param2 string) error {
 	f.LinkCall.mutex.Lock()
 	defer f.LinkCall.mutex.Unlock()
 	f.LinkCall.CallCount++
-	f.LinkCall.Receives.WorkingDir = param1
+	f.LinkCall.Receives.DotnetRoot = param1
 	f.LinkCall.Receives.LayerPath = param2
 	if f.LinkCall.Stub != nil {
 		return f.LinkCall.Stub(param1, param2)
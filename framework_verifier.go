@@ -0,0 +1,77 @@
+package dotnetcoreaspnet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// requiredFrameworkFiles are the files that every installation of the
+// Microsoft.AspNetCore.App shared framework contains.
+var requiredFrameworkFiles = []string{
+	"Microsoft.AspNetCore.App.deps.json",
+	"Microsoft.AspNetCore.dll",
+}
+
+type FrameworkVerifier struct{}
+
+func NewFrameworkVerifier() FrameworkVerifier {
+	return FrameworkVerifier{}
+}
+
+// Verify checks that delivering the given version of the dependency into the
+// layer produced a usable shared framework: the
+// shared/Microsoft.AspNetCore.App/<version> directory and its key files.
+// A mismatch usually means that the dependency archive is wrong or truncated.
+func (v FrameworkVerifier) Verify(layerPath, version string) error {
+	frameworkDir := filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App")
+
+	files, err := os.ReadDir(frameworkDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to verify .NET Core ASPNet %s installation: %s does not exist: the dependency archive may be wrong or truncated", version, frameworkDir)
+		}
+
+		return fmt.Errorf("failed to verify .NET Core ASPNet %s installation: %w", version, err)
+	}
+
+	var versions []string
+	found := false
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+
+		versions = append(versions, f.Name())
+		if f.Name() == version {
+			found = true
+		}
+	}
+
+	if !found {
+		sort.Strings(versions)
+		return fmt.Errorf("failed to verify .NET Core ASPNet %s installation: %s contains versions [%s] but not %s: the dependency archive may be wrong or truncated", version, frameworkDir, strings.Join(versions, ", "), version)
+	}
+
+	for _, file := range requiredFrameworkFiles {
+		path := filepath.Join(frameworkDir, version, file)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to verify .NET Core ASPNet %s installation: %s does not exist: the dependency archive may be wrong or truncated", version, path)
+			}
+
+			return fmt.Errorf("failed to verify .NET Core ASPNet %s installation: %w", version, err)
+		}
+
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("failed to verify .NET Core ASPNet %s installation: %s is not a file", version, path)
+		}
+	}
+
+	return nil
+}
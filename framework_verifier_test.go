@@ -0,0 +1,86 @@
+package dotnetcoreaspnet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testFrameworkVerifier(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath    string
+		frameworkDir string
+		verifier     dotnetcoreaspnet.FrameworkVerifier
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		frameworkDir = filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App")
+		Expect(os.MkdirAll(filepath.Join(frameworkDir, "6.0.13"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.App.deps.json"), []byte("{}"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.dll"), nil, 0600)).To(Succeed())
+
+		verifier = dotnetcoreaspnet.NewFrameworkVerifier()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	it("accepts a complete installation", func() {
+		Expect(verifier.Verify(layerPath, "6.0.13")).To(Succeed())
+	})
+
+	context("failure cases", func() {
+		context("when the framework directory is missing", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(layerPath, "shared"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := verifier.Verify(layerPath, "6.0.13")
+				Expect(err).To(MatchError(ContainSubstring("failed to verify .NET Core ASPNet 6.0.13 installation: %s does not exist", frameworkDir)))
+			})
+		})
+
+		context("when the version directory does not match the version", func() {
+			it("returns an error", func() {
+				err := verifier.Verify(layerPath, "6.0.14")
+				Expect(err).To(MatchError(ContainSubstring("failed to verify .NET Core ASPNet 6.0.14 installation: %s contains versions [6.0.13] but not 6.0.14", frameworkDir)))
+			})
+		})
+
+		context("when a key file is missing", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.App.deps.json"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := verifier.Verify(layerPath, "6.0.13")
+				Expect(err).To(MatchError(ContainSubstring("failed to verify .NET Core ASPNet 6.0.13 installation: %s does not exist", filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.App.deps.json"))))
+			})
+		})
+
+		context("when a key file is a directory", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.dll"))).To(Succeed())
+				Expect(os.Mkdir(filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.dll"), os.ModePerm)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				err := verifier.Verify(layerPath, "6.0.13")
+				Expect(err).To(MatchError(ContainSubstring("%s is not a file", filepath.Join(frameworkDir, "6.0.13", "Microsoft.AspNetCore.dll"))))
+			})
+		})
+	})
+}
@@ -9,9 +9,21 @@ import (
 
 func TestUnitDotnetCoreAspnet(t *testing.T) {
 	suite := spec.New("dotnet-core-aspnet", spec.Report(report.Terminal{}))
+	suite("ApplicationAnalyzer", testApplicationAnalyzer)
+	suite("ArchiveCache", testArchiveCache)
 	suite("Build", testBuild)
 	suite("BuildpackYMLParser", testBuildpackYMLParser)
+	suite("ContentManifest", testContentManifest)
+	suite("DependencyCatalog", testDependencyCatalog)
+	suite("DependencySBOM", testDependencySBOM)
 	suite("Detect", testDetect)
 	suite("DotnetRootLinker", testDotnetRootLinker)
+	suite("FrameworkVerifier", testFrameworkVerifier)
+	suite("ProjectFileParser", testProjectFileParser)
+	suite("RollForwardPolicy", testRollForwardPolicy)
+	suite("RuntimeCompatibilityValidator", testRuntimeCompatibilityValidator)
+	suite("RuntimeConfigParser", testRuntimeConfigParser)
+	suite("Target", testTarget)
+	suite("VersionConflicts", testVersionConflicts)
 	suite.Run(t)
 }
@@ -86,8 +86,14 @@ func testDefault(t *testing.T, context spec.G, it spec.S) {
 				MatchRegexp(`    Installing .NET Core ASPNet \d+\.\d+\.\d+`),
 				MatchRegexp(`      Completed in ([0-9]*(\.[0-9]*)?[a-z]+)+`),
 				"",
+				"  Configuring build environment",
+				fmt.Sprintf(`    DOTNET_ASPNETCORE_PATH    -> "/layers/%s/dotnet-core-aspnet/shared/Microsoft.AspNetCore.App"`, strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    DOTNET_ASPNETCORE_VERSION -> "\d+\.\d+\.\d+"`),
+				"",
 				"  Configuring launch environment",
-				`    DOTNET_ROOT -> "/workspace/.dotnet_root"`,
+				fmt.Sprintf(`    DOTNET_ASPNETCORE_PATH    -> "/layers/%s/dotnet-core-aspnet/shared/Microsoft.AspNetCore.App"`, strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    DOTNET_ASPNETCORE_VERSION -> "\d+\.\d+\.\d+"`),
+				`    DOTNET_ROOT               -> "/workspace/.dotnet_root"`,
 			))
 
 			container, err = docker.Container.Run.
@@ -171,8 +177,14 @@ func testDefault(t *testing.T, context spec.G, it spec.S) {
 				MatchRegexp(`    Installing .NET Core ASPNet 7\.0\.\d+`),
 				MatchRegexp(`      Completed in ([0-9]*(\.[0-9]*)?[a-z]+)+`),
 				"",
+				"  Configuring build environment",
+				fmt.Sprintf(`    DOTNET_ASPNETCORE_PATH    -> "/layers/%s/dotnet-core-aspnet/shared/Microsoft.AspNetCore.App"`, strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    DOTNET_ASPNETCORE_VERSION -> "7\.0\.\d+"`),
+				"",
 				"  Configuring launch environment",
-				`    DOTNET_ROOT -> "/workspace/.dotnet_root"`,
+				fmt.Sprintf(`    DOTNET_ASPNETCORE_PATH    -> "/layers/%s/dotnet-core-aspnet/shared/Microsoft.AspNetCore.App"`, strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				MatchRegexp(`    DOTNET_ASPNETCORE_VERSION -> "7\.0\.\d+"`),
+				`    DOTNET_ROOT               -> "/workspace/.dotnet_root"`,
 			))
 		})
 	}, spec.Sequential())
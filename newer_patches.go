@@ -0,0 +1,27 @@
+package dotnetcoreaspnet
+
+import "github.com/Masterminds/semver"
+
+// newerPatches returns the dependencies in the catalog that are later patches
+// of the same major.minor line as the given version, in ascending order.
+// Prereleases are only included when allowPrerelease is set.
+func newerPatches(catalogDeps []CatalogDependency, version string, allowPrerelease bool) []CatalogDependency {
+	current, err := semver.NewVersion(version)
+	if err != nil {
+		return nil
+	}
+
+	var patches []CatalogDependency
+	for _, dependency := range catalogDeps {
+		v, err := semver.NewVersion(dependency.Version)
+		if err != nil || (!allowPrerelease && v.Prerelease() != "") {
+			continue
+		}
+
+		if v.Major() == current.Major() && v.Minor() == current.Minor() && v.GreaterThan(current) {
+			patches = append(patches, dependency)
+		}
+	}
+
+	return patches
+}
@@ -0,0 +1,147 @@
+package dotnetcoreaspnet
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+type ProjectFileParser struct{}
+
+func NewProjectFileParser() ProjectFileParser {
+	return ProjectFileParser{}
+}
+
+func (p ProjectFileParser) FindProjectFile(root string) (string, error) {
+	var files []string
+	for _, pattern := range []string{"*.csproj", "*.fsproj", "*.vbproj"} {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return "", err
+		}
+
+		files = append(files, matches...)
+	}
+
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(files)
+
+	return files[0], nil
+}
+
+func (p ProjectFileParser) ParseVersion(path string) (string, error) {
+	project, err := parseProjectFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !project.usesASPNET() {
+		return "", nil
+	}
+
+	var frameworks []string
+	for _, group := range project.PropertyGroups {
+		frameworks = append(frameworks, strings.Split(group.TargetFramework, ";")...)
+		frameworks = append(frameworks, strings.Split(group.TargetFrameworks, ";")...)
+	}
+
+	var versions []*semver.Version
+	for _, framework := range frameworks {
+		version, ok := targetFrameworkVersion(strings.TrimSpace(framework))
+		if ok {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return "", nil
+	}
+
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+
+	return fmt.Sprintf("%d.%d.*", versions[0].Major(), versions[0].Minor()), nil
+}
+
+// UsesASPNET returns whether the project at the given path uses ASP.NET Core,
+// either through the web SDK or a reference to the shared framework.
+func (p ProjectFileParser) UsesASPNET(path string) (bool, error) {
+	project, err := parseProjectFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	return project.usesASPNET(), nil
+}
+
+type projectFile struct {
+	SDK            string `xml:"Sdk,attr"`
+	PropertyGroups []struct {
+		TargetFramework  string `xml:"TargetFramework"`
+		TargetFrameworks string `xml:"TargetFrameworks"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		FrameworkReferences []struct {
+			Include string `xml:"Include,attr"`
+		} `xml:"FrameworkReference"`
+	} `xml:"ItemGroup"`
+}
+
+func parseProjectFile(path string) (projectFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return projectFile{}, err
+	}
+	defer file.Close()
+
+	var project projectFile
+	err = xml.NewDecoder(file).Decode(&project)
+	if err != nil {
+		return projectFile{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return project, nil
+}
+
+func (project projectFile) usesASPNET() bool {
+	// the SDK attribute may include a version, e.g. Microsoft.NET.Sdk.Web/6.0.0
+	if strings.Split(project.SDK, "/")[0] == "Microsoft.NET.Sdk.Web" {
+		return true
+	}
+
+	for _, group := range project.ItemGroups {
+		for _, reference := range group.FrameworkReferences {
+			if reference.Include == "Microsoft.AspNetCore.App" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// targetFrameworkVersion converts a target framework moniker, such as net6.0,
+// net7.0-windows or netcoreapp3.1, into the framework version it targets. It
+// ignores monikers for frameworks that are not .NET Core (e.g. net48 or
+// netstandard2.0).
+func targetFrameworkVersion(tfm string) (*semver.Version, bool) {
+	matches := regexp.MustCompile(`^net(?:coreapp)?(\d+)\.(\d+)(?:-.*)?$`).FindStringSubmatch(tfm)
+	if matches == nil {
+		return nil, false
+	}
+
+	version, err := semver.NewVersion(fmt.Sprintf("%s.%s.0", matches[1], matches[2]))
+	if err != nil {
+		return nil, false
+	}
+
+	return version, true
+}
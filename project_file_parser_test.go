@@ -0,0 +1,175 @@
+package dotnetcoreaspnet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testProjectFileParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		parser     dotnetcoreaspnet.ProjectFileParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		parser = dotnetcoreaspnet.NewProjectFileParser()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("FindProjectFile", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "some-app.fsproj"), nil, 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "other-app.vbproj"), nil, 0600)).To(Succeed())
+		})
+
+		it("returns the first project file in the directory", func() {
+			path, err := parser.FindProjectFile(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal(filepath.Join(workingDir, "other-app.vbproj")))
+		})
+
+		context("when there is no project file", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "some-app.fsproj"))).To(Succeed())
+				Expect(os.Remove(filepath.Join(workingDir, "other-app.vbproj"))).To(Succeed())
+			})
+
+			it("returns an empty path", func() {
+				path, err := parser.FindProjectFile(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(path).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the root is a malformed glob", func() {
+				it("returns an error", func() {
+					_, err := parser.FindProjectFile(`\`)
+					Expect(err).To(MatchError(ContainSubstring("syntax error in pattern")))
+				})
+			})
+		})
+	})
+
+	context("ParseVersion", func() {
+		var path string
+
+		it.Before(func() {
+			path = filepath.Join(workingDir, "some-app.csproj")
+		})
+
+		it("returns the framework version of a web project", func() {
+			Expect(os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net6.0</TargetFramework>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+
+			version, err := parser.ParseVersion(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("6.0.*"))
+		})
+
+		context("when the project references the ASP.NET framework", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>netcoreapp3.1</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <FrameworkReference Include="Microsoft.AspNetCore.App" />
+  </ItemGroup>
+</Project>`), 0600)).To(Succeed())
+			})
+
+			it("returns the framework version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("3.1.*"))
+			})
+		})
+
+		context("when the project targets multiple frameworks", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk.Web/6.0.0">
+  <PropertyGroup>
+    <TargetFrameworks>net48;net7.0-windows;net6.0</TargetFrameworks>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+			})
+
+			it("returns the highest framework version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(Equal("7.0.*"))
+			})
+		})
+
+		context("when the project does not use ASP.NET", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net6.0</TargetFramework>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("when the project does not target .NET Core", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>$(SomeProperty)</TargetFramework>
+  </PropertyGroup>
+</Project>`), 0600)).To(Succeed())
+			})
+
+			it("returns an empty version", func() {
+				version, err := parser.ParseVersion(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(version).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the project file cannot be opened", func() {
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(filepath.Join(workingDir, "missing.csproj"))
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+
+			context("when the project file is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(path, []byte(`<<<`), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.ParseVersion(path)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+		})
+	})
+}
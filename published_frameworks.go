@@ -0,0 +1,55 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// publishFrameworks records the installed versions of the ASP.NET Core
+// framework for later buildpacks, both in the environment and in the
+// aspnetcore.toml file at the root of the layer.
+func publishFrameworks(layer *packit.Layer, deps []postal.Dependency) error {
+	frameworkPath := filepath.Join(layer.Path, "shared", "Microsoft.AspNetCore.App")
+
+	type framework struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Path    string `toml:"path"`
+	}
+
+	var installed struct {
+		Frameworks []framework `toml:"frameworks"`
+	}
+
+	var versions []string
+	for _, dependency := range deps {
+		versions = append(versions, dependency.Version)
+		installed.Frameworks = append(installed.Frameworks, framework{
+			Name:    "Microsoft.AspNetCore.App",
+			Version: dependency.Version,
+			Path:    filepath.Join(frameworkPath, dependency.Version),
+		})
+	}
+
+	file, err := os.Create(filepath.Join(layer.Path, "aspnetcore.toml"))
+	if err != nil {
+		return fmt.Errorf("failed to write installed frameworks: %w", err)
+	}
+	defer file.Close()
+
+	err = toml.NewEncoder(file).Encode(installed)
+	if err != nil {
+		return fmt.Errorf("failed to write installed frameworks: %w", err)
+	}
+
+	layer.SharedEnv.Override("DOTNET_ASPNETCORE_VERSION", strings.Join(versions, ","))
+	layer.SharedEnv.Override("DOTNET_ASPNETCORE_PATH", frameworkPath)
+
+	return nil
+}
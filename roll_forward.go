@@ -0,0 +1,168 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// RollForwardPolicy mirrors the rollForward setting that the .NET host uses
+// to select a framework version for an application. See
+// https://learn.microsoft.com/en-us/dotnet/core/versions/selection#framework-dependent-apps-roll-forward
+type RollForwardPolicy string
+
+const (
+	RollForwardDisable     RollForwardPolicy = "Disable"
+	RollForwardLatestPatch RollForwardPolicy = "LatestPatch"
+	RollForwardMinor       RollForwardPolicy = "Minor"
+	RollForwardLatestMinor RollForwardPolicy = "LatestMinor"
+	RollForwardMajor       RollForwardPolicy = "Major"
+	RollForwardLatestMajor RollForwardPolicy = "LatestMajor"
+)
+
+var exactVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// ParseRollForwardPolicy returns the policy with the given name. Like the
+// .NET host, the name is matched case-insensitively.
+func ParseRollForwardPolicy(name string) (RollForwardPolicy, error) {
+	for _, policy := range []RollForwardPolicy{
+		RollForwardDisable,
+		RollForwardLatestPatch,
+		RollForwardMinor,
+		RollForwardLatestMinor,
+		RollForwardMajor,
+		RollForwardLatestMajor,
+	} {
+		if strings.EqualFold(name, string(policy)) {
+			return policy, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported roll-forward policy %q: must be one of Disable, LatestPatch, Minor, LatestMinor, Major or LatestMajor", name)
+}
+
+// Constraints returns the version constraints, in order of preference, that a
+// framework must satisfy for the .NET host to roll the given version forward
+// onto it. Like the host, the Minor and Major policies roll forward to the
+// lowest higher minor or major version among the available ones, and then to
+// the latest patch of that version. Versions that are not exact (e.g.
+// "6.0.*") are returned unchanged.
+func (p RollForwardPolicy) Constraints(version string, available []string) ([]string, error) {
+	if !exactVersionPattern.MatchString(version) {
+		return []string{version}, nil
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	latestPatch := fmt.Sprintf(">= %s, < %d.%d.0", version, v.Major(), v.Minor()+1)
+
+	switch p {
+	case RollForwardDisable:
+		return []string{version}, nil
+	case RollForwardLatestPatch:
+		return []string{latestPatch}, nil
+	case RollForwardMinor:
+		return append([]string{latestPatch}, lowestHigherMinor(v, available)...), nil
+	case RollForwardLatestMinor:
+		return []string{fmt.Sprintf(">= %s, < %d.0.0", version, v.Major()+1)}, nil
+	case RollForwardMajor:
+		constraints := append([]string{latestPatch}, lowestHigherMinor(v, available)...)
+		return append(constraints, lowestHigherMajor(v, available)...), nil
+	case RollForwardLatestMajor:
+		return []string{fmt.Sprintf(">= %s", version)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported roll-forward policy %q", p)
+	}
+}
+
+// lowestHigherMinor returns the constraint matching the patches of the lowest
+// available minor version above that of the given version within its major
+// version, if any.
+func lowestHigherMinor(v *semver.Version, available []string) []string {
+	var lowest *semver.Version
+	for _, version := range available {
+		candidate, err := semver.NewVersion(version)
+		if err != nil || candidate.Major() != v.Major() || candidate.Minor() <= v.Minor() {
+			continue
+		}
+
+		if lowest == nil || candidate.Minor() < lowest.Minor() {
+			lowest = candidate
+		}
+	}
+
+	if lowest == nil {
+		return nil
+	}
+
+	return []string{patchRange(lowest.Major(), lowest.Minor())}
+}
+
+// lowestHigherMajor returns the constraint matching the patches of the lowest
+// minor version of the lowest available major version above that of the
+// given version, if any.
+func lowestHigherMajor(v *semver.Version, available []string) []string {
+	var lowest *semver.Version
+	for _, version := range available {
+		candidate, err := semver.NewVersion(version)
+		if err != nil || candidate.Major() <= v.Major() {
+			continue
+		}
+
+		if lowest == nil || candidate.Major() < lowest.Major() || (candidate.Major() == lowest.Major() && candidate.Minor() < lowest.Minor()) {
+			lowest = candidate
+		}
+	}
+
+	if lowest == nil {
+		return nil
+	}
+
+	return []string{patchRange(lowest.Major(), lowest.Minor())}
+}
+
+// patchRange returns the constraint matching every patch of the given
+// major.minor version.
+func patchRange(major, minor int64) string {
+	return fmt.Sprintf(">= %d.%d.0, < %d.%d.0", major, minor, major, minor+1)
+}
+
+// rollForwardPolicy returns the roll-forward policy that applies to the
+// version requested by the given entry, along with where it was configured.
+// As with the .NET host, the environment takes precedence over the policy
+// declared in the runtimeconfig.json. Versions requested through other
+// sources are only rolled forward when a policy is set in the environment.
+func rollForwardPolicy(entry packit.BuildpackPlanEntry) (RollForwardPolicy, string, error) {
+	name, source := "", ""
+	for _, env := range []string{"BP_DOTNET_ROLL_FORWARD", "DOTNET_ROLL_FORWARD"} {
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			name, source = value, env
+			break
+		}
+	}
+
+	if name == "" {
+		if s, _ := entry.Metadata["version-source"].(string); s != "runtimeconfig.json" {
+			return "", "", nil
+		}
+
+		name, source = string(RollForwardMinor), "default"
+		if value, ok := entry.Metadata["roll-forward"].(string); ok && value != "" {
+			name, source = value, "runtimeconfig.json"
+		}
+	}
+
+	policy, err := ParseRollForwardPolicy(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	return policy, source, nil
+}
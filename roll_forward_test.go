@@ -0,0 +1,74 @@
+package dotnetcoreaspnet_test
+
+import (
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRollForwardPolicy(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseRollForwardPolicy", func() {
+		it("parses the policy name case-insensitively", func() {
+			policy, err := dotnetcoreaspnet.ParseRollForwardPolicy("latestpatch")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(dotnetcoreaspnet.RollForwardLatestPatch))
+		})
+
+		context("failure cases", func() {
+			context("when the policy is not supported", func() {
+				it("returns an error", func() {
+					_, err := dotnetcoreaspnet.ParseRollForwardPolicy("Sometimes")
+					Expect(err).To(MatchError(ContainSubstring(`unsupported roll-forward policy "Sometimes"`)))
+				})
+			})
+		})
+	})
+
+	context("Constraints", func() {
+		it("returns the constraints for each policy", func() {
+			available := []string{"5.0.17", "6.0.0", "6.2.1", "6.2.4", "6.3.0", "8.1.0", "8.0.3", "9.0.0"}
+			for policy, expected := range map[dotnetcoreaspnet.RollForwardPolicy][]string{
+				dotnetcoreaspnet.RollForwardDisable:     {"6.0.1"},
+				dotnetcoreaspnet.RollForwardLatestPatch: {">= 6.0.1, < 6.1.0"},
+				dotnetcoreaspnet.RollForwardMinor:       {">= 6.0.1, < 6.1.0", ">= 6.2.0, < 6.3.0"},
+				dotnetcoreaspnet.RollForwardLatestMinor: {">= 6.0.1, < 7.0.0"},
+				dotnetcoreaspnet.RollForwardMajor:       {">= 6.0.1, < 6.1.0", ">= 6.2.0, < 6.3.0", ">= 8.0.0, < 8.1.0"},
+				dotnetcoreaspnet.RollForwardLatestMajor: {">= 6.0.1"},
+			} {
+				constraints, err := policy.Constraints("6.0.1", available)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(constraints).To(Equal(expected), string(policy))
+			}
+		})
+
+		context("when the version is not exact", func() {
+			it("returns the version unchanged", func() {
+				constraints, err := dotnetcoreaspnet.RollForwardMajor.Constraints("6.0.*", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(constraints).To(Equal([]string{"6.0.*"}))
+			})
+		})
+
+		context("when no higher minor or major version is available", func() {
+			it("only returns the patches of the requested version", func() {
+				constraints, err := dotnetcoreaspnet.RollForwardMajor.Constraints("6.0.1", []string{"5.0.17", "6.0.4"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(constraints).To(Equal([]string{">= 6.0.1, < 6.1.0"}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the policy is not supported", func() {
+				it("returns an error", func() {
+					_, err := dotnetcoreaspnet.RollForwardPolicy("Sometimes").Constraints("6.0.1", nil)
+					Expect(err).To(MatchError(`unsupported roll-forward policy "Sometimes"`))
+				})
+			})
+		})
+	})
+}
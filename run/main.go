@@ -15,23 +15,36 @@ import (
 
 type Generator struct{}
 
-func (f Generator) GenerateFromDependency(dependency postal.Dependency, path string) (sbom.SBOM, error) {
-	return sbom.GenerateFromDependency(dependency, path)
+func (f Generator) GenerateFromDependencies(dependencies []postal.Dependency, path string) (sbom.SBOM, error) {
+	return dotnetcoreaspnet.GenerateFromDependencies(dependencies, path)
 }
 
 func main() {
 	buildpackYMLParser := dotnetcoreaspnet.NewBuildpackYMLParser()
+	runtimeConfigParser := dotnetcoreaspnet.NewRuntimeConfigParser()
+	projectParser := dotnetcoreaspnet.NewProjectFileParser()
 	logEmitter := scribe.NewEmitter(os.Stdout).WithLevel(os.Getenv("BP_LOG_LEVEL"))
 	entryResolver := draft.NewPlanner()
-	dependencyManager := postal.NewService(cargo.NewTransport())
-	dotnetRootLinker := dotnetcoreaspnet.NewDotnetRootLinker()
+	transport := cargo.NewTransport()
+	dependencyManager := postal.NewService(transport)
+	archiveCache := dotnetcoreaspnet.NewArchiveCache(transport, logEmitter)
+	dependencyCatalog := dotnetcoreaspnet.NewDependencyCatalog()
+	applicationAnalyzer := dotnetcoreaspnet.NewApplicationAnalyzer(logEmitter)
+	dotnetRootLinker := dotnetcoreaspnet.NewDotnetRootLinker(logEmitter).WithMode(dotnetcoreaspnet.LinkMode(os.Getenv("BP_DOTNET_ROOT_LINK_MODE")))
+	compatibilityValidator := dotnetcoreaspnet.NewRuntimeCompatibilityValidator(logEmitter)
+	frameworkVerifier := dotnetcoreaspnet.NewFrameworkVerifier()
 
 	packit.Run(
-		dotnetcoreaspnet.Detect(buildpackYMLParser),
+		dotnetcoreaspnet.Detect(buildpackYMLParser, runtimeConfigParser, projectParser),
 		dotnetcoreaspnet.Build(
 			entryResolver,
 			dependencyManager,
+			archiveCache,
+			dependencyCatalog,
+			applicationAnalyzer,
 			dotnetRootLinker,
+			compatibilityValidator,
+			frameworkVerifier,
 			Generator{},
 			logEmitter,
 			chronos.DefaultClock,
@@ -0,0 +1,176 @@
+package dotnetcoreaspnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+type RuntimeCompatibilityValidator struct {
+	logger scribe.Emitter
+}
+
+func NewRuntimeCompatibilityValidator(logger scribe.Emitter) RuntimeCompatibilityValidator {
+	return RuntimeCompatibilityValidator{
+		logger: logger,
+	}
+}
+
+// Validate checks that every ASP.NET Core framework installed in the given
+// layer has a compatible Microsoft.NETCore.App runtime in one of the given
+// dotnet roots. ASP.NET Core requires a runtime with the same major and minor
+// version and at least the patch version that it was built against. The .NET
+// Core Runtime buildpack installs a single runtime, so when several versions
+// are installed side-by-side, the versions whose release has no runtime at all
+// are only reported, as long as one of the versions has a compatible runtime.
+func (v RuntimeCompatibilityValidator) Validate(layerPath string, dotnetRoots []string) error {
+	frameworks, err := frameworkVersions(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App"))
+	if err != nil {
+		return err
+	}
+
+	var runtimes []*semver.Version
+	for _, root := range dotnetRoots {
+		versions, err := frameworkVersions(filepath.Join(root, "shared", "Microsoft.NETCore.App"))
+		if err != nil {
+			return err
+		}
+
+		runtimes = append(runtimes, versions...)
+	}
+
+	if len(frameworks) == 0 {
+		return nil
+	}
+
+	if len(runtimes) == 0 {
+		v.logger.Subprocess("WARNING: Unable to validate the .NET Core ASPNet installation: no Microsoft.NETCore.App runtime was found in %s", strings.Join(dotnetRoots, ", "))
+		v.logger.Break()
+		return nil
+	}
+
+	var installed []string
+	for _, runtime := range runtimes {
+		installed = append(installed, runtime.Original())
+	}
+	sort.Strings(installed)
+
+	var missing []error
+	for _, framework := range frameworks {
+		minimum, err := requiredRuntimeVersion(layerPath, framework)
+		if err != nil {
+			return err
+		}
+
+		constraint, err := semver.NewConstraint(fmt.Sprintf(">= %s, < %d.%d.0", minimum, minimum.Major(), minimum.Minor()+1))
+		if err != nil {
+			return err
+		}
+
+		compatible, sameRelease := false, false
+		for _, runtime := range runtimes {
+			if constraint.Check(runtime) {
+				compatible = true
+				break
+			}
+
+			if runtime.Major() == minimum.Major() && runtime.Minor() == minimum.Minor() {
+				sameRelease = true
+			}
+		}
+
+		if compatible {
+			continue
+		}
+
+		err = fmt.Errorf("ASP.NET Core %s requires Microsoft.NETCore.App %s or a later %d.%d patch, but only [%s] is installed: make sure the dotnet-runtime version matches the dotnet-aspnetcore version",
+			framework.Original(), minimum.Original(), minimum.Major(), minimum.Minor(), strings.Join(installed, ", "))
+		if sameRelease {
+			return err
+		}
+
+		missing = append(missing, err)
+	}
+
+	if len(missing) == len(frameworks) {
+		return missing[0]
+	}
+
+	for _, err := range missing {
+		v.logger.Subprocess("WARNING: %s", err)
+	}
+	if len(missing) > 0 {
+		v.logger.Subprocess("Applications that target these versions only start when another source provides their runtime.")
+		v.logger.Break()
+	}
+
+	return nil
+}
+
+// frameworkVersions returns the versions of a shared framework found in the
+// given framework directory.
+func frameworkVersions(dir string) ([]*semver.Version, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var versions []*semver.Version
+	for _, f := range files {
+		version, err := semver.NewVersion(f.Name())
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// requiredRuntimeVersion returns the Microsoft.NETCore.App version referenced
+// by the runtimeconfig.json of the given ASP.NET Core framework. When the file
+// is missing, the first patch of the framework's major and minor is assumed.
+func requiredRuntimeVersion(layerPath string, framework *semver.Version) (*semver.Version, error) {
+	path := filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", framework.Original(), "Microsoft.AspNetCore.App.runtimeconfig.json")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return semver.NewVersion(fmt.Sprintf("%d.%d.0", framework.Major(), framework.Minor()))
+		}
+
+		return nil, err
+	}
+
+	var config struct {
+		RuntimeOptions struct {
+			Framework struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"framework"`
+		} `json:"runtimeOptions"`
+	}
+
+	err = json.Unmarshal(content, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if config.RuntimeOptions.Framework.Name != "Microsoft.NETCore.App" {
+		return semver.NewVersion(fmt.Sprintf("%d.%d.0", framework.Major(), framework.Minor()))
+	}
+
+	return semver.NewVersion(config.RuntimeOptions.Framework.Version)
+}
@@ -0,0 +1,178 @@
+package dotnetcoreaspnet_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRuntimeCompatibilityValidator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath  string
+		dotnetRoot string
+		buffer     *bytes.Buffer
+		validator  dotnetcoreaspnet.RuntimeCompatibilityValidator
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "layer-path")
+		Expect(err).NotTo(HaveOccurred())
+
+		dotnetRoot, err = os.MkdirTemp("", "dotnet-root")
+		Expect(err).NotTo(HaveOccurred())
+
+		aspnetDir := filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13")
+		Expect(os.MkdirAll(aspnetDir, os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(aspnetDir, "Microsoft.AspNetCore.App.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "tfm": "net6.0",
+    "framework": {
+      "name": "Microsoft.NETCore.App",
+      "version": "6.0.13"
+    }
+  }
+}`), 0600)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		validator = dotnetcoreaspnet.NewRuntimeCompatibilityValidator(scribe.NewEmitter(buffer))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+		Expect(os.RemoveAll(dotnetRoot)).To(Succeed())
+	})
+
+	context("Validate", func() {
+		context("when a compatible runtime is installed", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "6.0.14"), os.ModePerm)).To(Succeed())
+			})
+
+			it("succeeds", func() {
+				err := validator.Validate(layerPath, []string{"some-missing-root", dotnetRoot})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+
+		context("when the framework does not declare the runtime it requires", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "Microsoft.AspNetCore.App.runtimeconfig.json"))).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "6.0.2"), os.ModePerm)).To(Succeed())
+			})
+
+			it("only requires a runtime with the same major and minor", func() {
+				err := validator.Validate(layerPath, []string{dotnetRoot})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		context("when no runtime is installed", func() {
+			it("warns that the installation could not be validated", func() {
+				err := validator.Validate(layerPath, []string{dotnetRoot})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring("WARNING: Unable to validate the .NET Core ASPNet installation: no Microsoft.NETCore.App runtime was found in " + dotnetRoot))
+			})
+		})
+
+		context("when several versions are installed and only one has a runtime", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "7.0.2"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "6.0.13"), os.ModePerm)).To(Succeed())
+			})
+
+			it("warns about the versions without a runtime", func() {
+				err := validator.Validate(layerPath, []string{dotnetRoot})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring("WARNING: ASP.NET Core 7.0.2 requires Microsoft.NETCore.App 7.0.0 or a later 7.0 patch, but only [6.0.13] is installed"))
+				Expect(buffer.String()).To(ContainSubstring("Applications that target these versions only start when another source provides their runtime."))
+			})
+		})
+
+		context("when no ASP.NET framework is installed", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(layerPath, "shared"))).To(Succeed())
+			})
+
+			it("succeeds", func() {
+				err := validator.Validate(layerPath, []string{dotnetRoot})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the installed runtime patch is too old", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "6.0.12"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(layerPath, []string{dotnetRoot})
+					Expect(err).To(MatchError("ASP.NET Core 6.0.13 requires Microsoft.NETCore.App 6.0.13 or a later 6.0 patch, but only [6.0.12] is installed: make sure the dotnet-runtime version matches the dotnet-aspnetcore version"))
+				})
+			})
+
+			context("when the installed runtime is from another release", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "7.0.1"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(layerPath, []string{dotnetRoot})
+					Expect(err).To(MatchError(ContainSubstring("but only [7.0.1] is installed")))
+				})
+			})
+
+			context("when several versions are installed and the runtime of one is too old", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "7.0.2"), os.ModePerm)).To(Succeed())
+					Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "6.0.12"), os.ModePerm)).To(Succeed())
+					Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "7.0.2"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(layerPath, []string{dotnetRoot})
+					Expect(err).To(MatchError(ContainSubstring("ASP.NET Core 6.0.13 requires Microsoft.NETCore.App 6.0.13 or a later 6.0 patch, but only [6.0.12, 7.0.2] is installed")))
+				})
+			})
+
+			context("when the framework runtimeconfig.json is malformed", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(dotnetRoot, "shared", "Microsoft.NETCore.App", "6.0.13"), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App", "6.0.13", "Microsoft.AspNetCore.App.runtimeconfig.json"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(layerPath, []string{dotnetRoot})
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+				})
+			})
+
+			context("when the framework directory cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Chmod(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App"), 0000)).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Chmod(filepath.Join(layerPath, "shared", "Microsoft.AspNetCore.App"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := validator.Validate(layerPath, []string{dotnetRoot})
+					Expect(err).To(MatchError(ContainSubstring("permission denied")))
+				})
+			})
+		})
+	})
+}
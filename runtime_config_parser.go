@@ -0,0 +1,80 @@
+package dotnetcoreaspnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type RuntimeConfig struct {
+	Path          string
+	ASPNETVersion string
+	RollForward   string
+	SelfContained bool
+}
+
+type RuntimeConfigParser struct{}
+
+func NewRuntimeConfigParser() RuntimeConfigParser {
+	return RuntimeConfigParser{}
+}
+
+// Parse parses the first runtimeconfig.json that matches the glob. When
+// several files match, such as when an app is published alongside its tools,
+// the first one in lexical order is used so that the result does not depend on
+// the order of the directory listing.
+func (p RuntimeConfigParser) Parse(glob string) (RuntimeConfig, error) {
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+
+	if len(files) == 0 {
+		return RuntimeConfig{}, nil
+	}
+
+	sort.Strings(files)
+
+	type framework struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	var data struct {
+		RuntimeOptions struct {
+			Framework          framework   `json:"framework"`
+			Frameworks         []framework `json:"frameworks"`
+			IncludedFrameworks []framework `json:"includedFrameworks"`
+			RollForward        string      `json:"rollForward"`
+		} `json:"runtimeOptions"`
+	}
+
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		return RuntimeConfig{}, err
+	}
+
+	err = json.Unmarshal(content, &data)
+	if err != nil {
+		return RuntimeConfig{}, fmt.Errorf("failed to parse %s: %w", files[0], err)
+	}
+
+	// self-contained apps list the frameworks they were published with as
+	// includedFrameworks instead of referencing a shared framework
+	config := RuntimeConfig{
+		Path:          files[0],
+		RollForward:   data.RuntimeOptions.RollForward,
+		SelfContained: len(data.RuntimeOptions.IncludedFrameworks) > 0,
+	}
+
+	frameworks := append([]framework{data.RuntimeOptions.Framework}, data.RuntimeOptions.Frameworks...)
+	for _, f := range frameworks {
+		if f.Name == "Microsoft.AspNetCore.App" {
+			config.ASPNETVersion = f.Version
+		}
+	}
+
+	return config, nil
+}
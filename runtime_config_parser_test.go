@@ -0,0 +1,186 @@
+package dotnetcoreaspnet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRuntimeConfigParser(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+		parser     dotnetcoreaspnet.RuntimeConfigParser
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		parser = dotnetcoreaspnet.NewRuntimeConfigParser()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("Parse", func() {
+		it("parses the ASP.NET version from the framework", func() {
+			err := os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "tfm": "net6.0",
+    "framework": {
+      "name": "Microsoft.AspNetCore.App",
+      "version": "6.0.0"
+    }
+  }
+}`), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			config, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(dotnetcoreaspnet.RuntimeConfig{
+				Path:          filepath.Join(workingDir, "some-app.runtimeconfig.json"),
+				ASPNETVersion: "6.0.0",
+			}))
+		})
+
+		context("when the ASP.NET framework is listed in frameworks", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "tfm": "net7.0",
+    "rollForward": "Major",
+    "frameworks": [
+      {
+        "name": "Microsoft.NETCore.App",
+        "version": "7.0.0"
+      },
+      {
+        "name": "Microsoft.AspNetCore.App",
+        "version": "7.0.1"
+      }
+    ]
+  }
+}`), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("parses the ASP.NET version and roll-forward policy", func() {
+				config, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.ASPNETVersion).To(Equal("7.0.1"))
+				Expect(config.RollForward).To(Equal("Major"))
+			})
+		})
+
+		context("when the app is self-contained", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "tfm": "net6.0",
+    "includedFrameworks": [
+      {
+        "name": "Microsoft.NETCore.App",
+        "version": "6.0.13"
+      },
+      {
+        "name": "Microsoft.AspNetCore.App",
+        "version": "6.0.13"
+      }
+    ]
+  }
+}`), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("marks the app as self-contained", func() {
+				config, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.SelfContained).To(BeTrue())
+				Expect(config.ASPNETVersion).To(BeEmpty())
+			})
+		})
+
+		context("when the app does not use ASP.NET", func() {
+			it.Before(func() {
+				err := os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+  "runtimeOptions": {
+    "framework": {
+      "name": "Microsoft.NETCore.App",
+      "version": "6.0.0"
+    }
+  }
+}`), 0600)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("returns an empty ASP.NET version", func() {
+				config, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.Path).To(Equal(filepath.Join(workingDir, "some-app.runtimeconfig.json")))
+				Expect(config.ASPNETVersion).To(BeEmpty())
+			})
+		})
+
+		context("when there is no runtimeconfig.json", func() {
+			it("returns an empty config", func() {
+				config, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config).To(Equal(dotnetcoreaspnet.RuntimeConfig{}))
+			})
+		})
+
+		context("when there are multiple runtimeconfig.json files", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`{
+					"runtimeOptions": {
+						"framework": { "name": "Microsoft.AspNetCore.App", "version": "6.0.0" }
+					}
+				}`), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "other-app.runtimeconfig.json"), []byte(`{
+					"runtimeOptions": {
+						"framework": { "name": "Microsoft.AspNetCore.App", "version": "7.0.0" }
+					}
+				}`), 0600)).To(Succeed())
+			})
+
+			it("parses the first one in lexical order", func() {
+				config, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config).To(Equal(dotnetcoreaspnet.RuntimeConfig{
+					Path:          filepath.Join(workingDir, "other-app.runtimeconfig.json"),
+					ASPNETVersion: "7.0.0",
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the glob is malformed", func() {
+				it("returns an error", func() {
+					_, err := parser.Parse(`\`)
+					Expect(err).To(MatchError(ContainSubstring("syntax error in pattern")))
+				})
+			})
+
+			context("when the runtimeconfig.json is malformed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "some-app.runtimeconfig.json"), []byte(`%%%`), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := parser.Parse(filepath.Join(workingDir, "*.runtimeconfig.json"))
+					Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+					Expect(err).To(MatchError(ContainSubstring("invalid character")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,38 @@
+package dotnetcoreaspnet
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// runtimeVersionExported returns whether the given value of RUNTIME_VERSION
+// was exported by a layer of an earlier buildpack, such as the .NET Core
+// Runtime buildpack, which sets it to the version of the runtime it installed.
+// Any other value was set by the user.
+func runtimeVersionExported(layersPath, value string) (bool, error) {
+	for _, dir := range []string{"env", "env.build"} {
+		files, err := filepath.Glob(filepath.Join(filepath.Dir(layersPath), "*", "*", dir, "RUNTIME_VERSION*"))
+		if err != nil {
+			return false, err
+		}
+
+		for _, file := range files {
+			switch filepath.Base(file) {
+			case "RUNTIME_VERSION", "RUNTIME_VERSION.override", "RUNTIME_VERSION.default":
+			default:
+				continue
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return false, err
+			}
+
+			if string(content) == value {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,54 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Target is the platform that the image is built for. Platforms that
+// implement Buildpack API 0.10 describe it through the CNB_TARGET_*
+// environment variables, while older platforms only provide a stack.
+type Target struct {
+	Stack         string
+	OS            string
+	Arch          string
+	DistroName    string
+	DistroVersion string
+}
+
+// NewTarget returns the target described by the platform environment. The
+// operating system and architecture default to those of the build.
+func NewTarget(stack string) Target {
+	target := Target{
+		Stack:         stack,
+		OS:            os.Getenv("CNB_TARGET_OS"),
+		Arch:          normalizeArch(os.Getenv("CNB_TARGET_ARCH")),
+		DistroName:    os.Getenv("CNB_TARGET_DISTRO_NAME"),
+		DistroVersion: os.Getenv("CNB_TARGET_DISTRO_VERSION"),
+	}
+
+	if target.OS == "" {
+		target.OS = runtime.GOOS
+	}
+
+	if target.Arch == "" {
+		target.Arch = runtime.GOARCH
+	}
+
+	return target
+}
+
+// HasDistro returns whether the platform described the distribution of the
+// target, in which case dependencies are matched on it instead of the stack.
+func (t Target) HasDistro() bool {
+	return t.DistroName != ""
+}
+
+func (t Target) String() string {
+	if t.HasDistro() {
+		return fmt.Sprintf("%s %s (%s/%s)", t.DistroName, t.DistroVersion, t.OS, t.Arch)
+	}
+
+	return fmt.Sprintf("%s (%s/%s)", t.Stack, t.OS, t.Arch)
+}
@@ -0,0 +1,56 @@
+package dotnetcoreaspnet_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testTarget(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("NewTarget", func() {
+		it("defaults to the stack and the platform of the build", func() {
+			target := dotnetcoreaspnet.NewTarget("some-stack")
+			Expect(target).To(Equal(dotnetcoreaspnet.Target{
+				Stack: "some-stack",
+				OS:    runtime.GOOS,
+				Arch:  runtime.GOARCH,
+			}))
+			Expect(target.HasDistro()).To(BeFalse())
+		})
+
+		context("when the platform describes the target", func() {
+			it.Before(func() {
+				Expect(os.Setenv("CNB_TARGET_OS", "linux")).To(Succeed())
+				Expect(os.Setenv("CNB_TARGET_ARCH", "aarch64")).To(Succeed())
+				Expect(os.Setenv("CNB_TARGET_DISTRO_NAME", "ubuntu")).To(Succeed())
+				Expect(os.Setenv("CNB_TARGET_DISTRO_VERSION", "22.04")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("CNB_TARGET_OS")).To(Succeed())
+				Expect(os.Unsetenv("CNB_TARGET_ARCH")).To(Succeed())
+				Expect(os.Unsetenv("CNB_TARGET_DISTRO_NAME")).To(Succeed())
+				Expect(os.Unsetenv("CNB_TARGET_DISTRO_VERSION")).To(Succeed())
+			})
+
+			it("reads it from the environment", func() {
+				target := dotnetcoreaspnet.NewTarget("")
+				Expect(target).To(Equal(dotnetcoreaspnet.Target{
+					OS:            "linux",
+					Arch:          "arm64",
+					DistroName:    "ubuntu",
+					DistroVersion: "22.04",
+				}))
+				Expect(target.HasDistro()).To(BeTrue())
+				Expect(target.String()).To(Equal("ubuntu 22.04 (linux/arm64)"))
+			})
+		})
+	})
+}
@@ -0,0 +1,59 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// aliasPattern matches the version aliases that are resolved against the
+// catalog: the lts, sts and latest channels, and major versions such as 6.
+var aliasPattern = regexp.MustCompile(`(?i)^(lts|sts|latest|\d+)$`)
+
+// resolveAlias returns the latest version in the catalog that the given alias
+// refers to.
+func resolveAlias(catalogDeps []CatalogDependency, id, alias string, allowPrerelease bool) (string, error) {
+	alias = strings.ToLower(alias)
+
+	dependency, ok := latestDependency(catalogDeps, func(dependency CatalogDependency) bool {
+		if !allowPrerelease && isPrerelease(dependency.Version) {
+			return false
+		}
+
+		switch alias {
+		case "latest":
+			return true
+		case "lts", "sts":
+			return dependency.SupportChannel() == alias
+		default:
+			return strconv.FormatInt(semver.MustParse(dependency.Version).Major(), 10) == alias
+		}
+	})
+	if ok {
+		return dependency.Version, nil
+	}
+
+	return "", fmt.Errorf("failed to resolve version alias %q: no version of %s matches", alias, id)
+}
+
+// withAliasSource returns the entry with the alias it requested appended to
+// its version source, so that the selected version is logged along with the
+// alias it was resolved from.
+func withAliasSource(entry packit.BuildpackPlanEntry) packit.BuildpackPlanEntry {
+	version, _ := entry.Metadata["version"].(string)
+	if !aliasPattern.MatchString(version) {
+		return entry
+	}
+
+	metadata := map[string]interface{}{}
+	for key, value := range entry.Metadata {
+		metadata[key] = value
+	}
+	metadata["version-source"] = fmt.Sprintf("%v, alias %s", entry.Metadata["version-source"], version)
+
+	return packit.BuildpackPlanEntry{Name: entry.Name, Metadata: metadata}
+}
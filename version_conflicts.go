@@ -0,0 +1,95 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+var majorMinorPattern = regexp.MustCompile(`^\s*[=v~^]*\s*(\d+)\.(\d+)(?:[.-]|$)`)
+
+// VersionConflict is a version request from a source other than the selected
+// one that asks for a major.minor version the selected source does not.
+type VersionConflict struct {
+	Source  string
+	Version string
+}
+
+func (c VersionConflict) String() string {
+	return fmt.Sprintf("%s requests %s", c.Source, c.Version)
+}
+
+// VersionConflicts returns the version requests from the other sources in
+// the build plan whose major.minor version is not requested by the selected
+// source. Requests that do not pin a major.minor version, such as "*" or an
+// alias, cannot conflict.
+func VersionConflicts(selected []packit.BuildpackPlanEntry, entries []packit.BuildpackPlanEntry) []VersionConflict {
+	if len(selected) == 0 {
+		return nil
+	}
+
+	selectedSource, _ := selected[0].Metadata["version-source"].(string)
+
+	selectedVersions := map[string]bool{}
+	for _, entry := range selected {
+		version, _ := entry.Metadata["version"].(string)
+		if mm, ok := majorMinor(version); ok {
+			selectedVersions[mm] = true
+		} else {
+			// the selected source accepts any major.minor version
+			return nil
+		}
+	}
+
+	var conflicts []VersionConflict
+	for _, entry := range entries {
+		source, _ := entry.Metadata["version-source"].(string)
+		version, _ := entry.Metadata["version"].(string)
+		if entry.Name != selected[0].Name || source == "" || source == selectedSource {
+			continue
+		}
+
+		mm, ok := majorMinor(version)
+		if !ok || selectedVersions[mm] {
+			continue
+		}
+
+		conflict := VersionConflict{Source: source, Version: version}
+		duplicate := false
+		for _, c := range conflicts {
+			if c == conflict {
+				duplicate = true
+			}
+		}
+
+		if !duplicate {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts
+}
+
+// majorMinor returns the major.minor version that the given version or
+// constraint pins, if any.
+func majorMinor(version string) (string, bool) {
+	matches := majorMinorPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s.%s", matches[1], matches[2]), true
+}
+
+// describeVersions lists the versions requested by the given entries.
+func describeVersions(entries []packit.BuildpackPlanEntry) string {
+	var versions []string
+	for _, entry := range entries {
+		version, _ := entry.Metadata["version"].(string)
+		versions = append(versions, version)
+	}
+
+	return strings.Join(versions, ", ")
+}
@@ -0,0 +1,147 @@
+package dotnetcoreaspnet_test
+
+import (
+	"testing"
+
+	dotnetcoreaspnet "github.com/paketo-buildpacks/dotnet-core-aspnet"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testVersionConflicts(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		entry = func(source, version string) packit.BuildpackPlanEntry {
+			return packit.BuildpackPlanEntry{
+				Name: "dotnet-aspnetcore",
+				Metadata: map[string]interface{}{
+					"version-source": source,
+					"version":        version,
+				},
+			}
+		}
+	)
+
+	context("VersionConflicts", func() {
+		it("returns the requests of other sources for another major.minor version", func() {
+			selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "6.0.*")}
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				selected[0],
+				entry("RUNTIME_VERSION", "7.0.1"),
+				entry("some-app.csproj", "6.0.0"),
+			})
+			Expect(conflicts).To(Equal([]dotnetcoreaspnet.VersionConflict{
+				{Source: "RUNTIME_VERSION", Version: "7.0.1"},
+			}))
+			Expect(conflicts[0].String()).To(Equal("RUNTIME_VERSION requests 7.0.1"))
+		})
+
+		it("reads the major.minor version of constraints with an operator or prefix", func() {
+			selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "v6.0.1")}
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				entry("RUNTIME_VERSION", "~6.0.1"),
+				entry("buildpack.yml", "^6.0"),
+				entry("some-app.csproj", "= 6.0.2"),
+				entry("runtimeconfig.json", "6.0.0-rc.2"),
+				entry("global.json", "~7.0.1"),
+				entry("other-app.csproj", "v8.0"),
+			})
+			Expect(conflicts).To(Equal([]dotnetcoreaspnet.VersionConflict{
+				{Source: "global.json", Version: "~7.0.1"},
+				{Source: "other-app.csproj", Version: "v8.0"},
+			}))
+		})
+
+		it("does not mistake a longer major or minor version for a shorter one", func() {
+			selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "6.1.*")}
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				entry("RUNTIME_VERSION", "6.10.2"),
+				entry("buildpack.yml", "16.1.0"),
+			})
+			Expect(conflicts).To(Equal([]dotnetcoreaspnet.VersionConflict{
+				{Source: "RUNTIME_VERSION", Version: "6.10.2"},
+				{Source: "buildpack.yml", Version: "16.1.0"},
+			}))
+		})
+
+		it("reports each conflicting request once", func() {
+			selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "6.0.*")}
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				entry("some-app.csproj", "7.0.0"),
+				entry("some-app.csproj", "7.0.0"),
+				entry("other-app.csproj", "7.0.0"),
+			})
+			Expect(conflicts).To(Equal([]dotnetcoreaspnet.VersionConflict{
+				{Source: "some-app.csproj", Version: "7.0.0"},
+				{Source: "other-app.csproj", Version: "7.0.0"},
+			}))
+		})
+
+		it("accepts any of the versions requested by the selected source", func() {
+			selected := []packit.BuildpackPlanEntry{
+				entry("BP_DOTNET_ASPNETCORE_VERSIONS", "6.0.*"),
+				entry("BP_DOTNET_ASPNETCORE_VERSIONS", "7.0.*"),
+			}
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				entry("some-app.csproj", "7.0.1"),
+				entry("other-app.csproj", "8.0.0"),
+			})
+			Expect(conflicts).To(Equal([]dotnetcoreaspnet.VersionConflict{
+				{Source: "other-app.csproj", Version: "8.0.0"},
+			}))
+		})
+
+		it("ignores requests that do not pin a major.minor version", func() {
+			selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "6.0.*")}
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				entry("RUNTIME_VERSION", "*"),
+				entry("buildpack.yml", "latest-lts"),
+				entry("some-app.csproj", "7"),
+				entry("other-app.csproj", ">= 7.0.0, < 8.0.0"),
+			})
+			Expect(conflicts).To(BeEmpty())
+		})
+
+		it("ignores requests for other dependencies or without a source", func() {
+			selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "6.0.*")}
+
+			other := entry("RUNTIME_VERSION", "7.0.1")
+			other.Name = "dotnet-runtime"
+
+			conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+				other,
+				entry("", "7.0.1"),
+			})
+			Expect(conflicts).To(BeEmpty())
+		})
+
+		context("when the selected source does not pin a major.minor version", func() {
+			it("returns no conflicts", func() {
+				selected := []packit.BuildpackPlanEntry{entry("BP_DOTNET_FRAMEWORK_VERSION", "latest")}
+
+				conflicts := dotnetcoreaspnet.VersionConflicts(selected, []packit.BuildpackPlanEntry{
+					entry("RUNTIME_VERSION", "7.0.1"),
+				})
+				Expect(conflicts).To(BeEmpty())
+			})
+		})
+
+		context("when no source is selected", func() {
+			it("returns no conflicts", func() {
+				conflicts := dotnetcoreaspnet.VersionConflicts(nil, []packit.BuildpackPlanEntry{
+					entry("RUNTIME_VERSION", "7.0.1"),
+				})
+				Expect(conflicts).To(BeEmpty())
+			})
+		})
+	})
+}
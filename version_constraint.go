@@ -0,0 +1,109 @@
+package dotnetcoreaspnet
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// resolveFunc resolves the dependency with the given id that satisfies the
+// version constraint.
+type resolveFunc func(id, version string) (postal.Dependency, error)
+
+// resolveDependency resolves the dependency for the version requested by the
+// given entry, honoring any roll-forward policy that applies to it.
+// Prerelease versions are only selected when allowPrerelease is set.
+func resolveDependency(resolve resolveFunc, catalogDeps []CatalogDependency, entry packit.BuildpackPlanEntry, allowPrerelease bool, logger scribe.Emitter) (postal.Dependency, error) {
+	version, _ := entry.Metadata["version"].(string)
+
+	// aliases already select the latest version available, so they are not
+	// rolled forward
+	if aliasPattern.MatchString(version) {
+		concrete, err := resolveAlias(catalogDeps, entry.Name, version, allowPrerelease)
+		if err != nil {
+			return postal.Dependency{}, err
+		}
+
+		return resolve(entry.Name, concrete)
+	}
+
+	constraints := []string{version}
+	policy, policySource, err := rollForwardPolicy(entry)
+	if err != nil {
+		return postal.Dependency{}, err
+	}
+
+	if policy != "" {
+		logger.Subprocess("Applying roll-forward policy %s (from %s)", policy, policySource)
+		logger.Break()
+
+		var available []string
+		for _, dependency := range catalogDeps {
+			if allowPrerelease || !isPrerelease(dependency.Version) {
+				available = append(available, dependency.Version)
+			}
+		}
+
+		constraints, err = policy.Constraints(version, available)
+		if err != nil {
+			return postal.Dependency{}, err
+		}
+	}
+
+	var dependency postal.Dependency
+	for _, constraint := range constraints {
+		dependency, err = resolveConstraint(resolve, catalogDeps, entry.Name, constraint, allowPrerelease)
+		if err == nil {
+			return dependency, nil
+		}
+	}
+
+	return postal.Dependency{}, err
+}
+
+// resolveConstraint resolves the dependency that matches the given constraint.
+// Version constraints never match prerelease versions unless they name one
+// themselves, so when prereleases are allowed the catalog is searched for a
+// later prerelease that would match the constraint as a release.
+func resolveConstraint(resolve resolveFunc, catalogDeps []CatalogDependency, id, constraint string, allowPrerelease bool) (postal.Dependency, error) {
+	dependency, err := resolve(id, constraint)
+	if !allowPrerelease {
+		if err == nil && isPrerelease(dependency.Version) {
+			return postal.Dependency{}, fmt.Errorf("failed to resolve %s %s: %s is a prerelease version, set BP_DOTNET_ALLOW_PRERELEASE=true to allow it", id, constraint, dependency.Version)
+		}
+
+		return dependency, err
+	}
+
+	if constraint == "" || constraint == "default" {
+		return dependency, err
+	}
+
+	c, cErr := semver.NewConstraint(constraint)
+	if cErr != nil {
+		return dependency, err
+	}
+
+	prerelease, ok := latestDependency(catalogDeps, func(dependency CatalogDependency) bool {
+		v := semver.MustParse(dependency.Version)
+		if v.Prerelease() == "" {
+			return false
+		}
+
+		release, _ := v.SetPrerelease("")
+		return c.Check(&release)
+	})
+	if !ok || (err == nil && !semver.MustParse(prerelease.Version).GreaterThan(semver.MustParse(dependency.Version))) {
+		return dependency, err
+	}
+
+	return resolve(id, prerelease.Version)
+}
+
+func isPrerelease(version string) bool {
+	v, err := semver.NewVersion(version)
+	return err == nil && v.Prerelease() != ""
+}